@@ -0,0 +1,43 @@
+package siv
+
+import (
+	"crypto/aes"
+	"errors"
+)
+
+var (
+	errAESKeySize    = errors.New("invalid AES-SIV key size: must be 32, 48 or 64 bytes")
+	errAES128KeySize = errors.New("invalid AES-SIV-128 key size: must be 32 bytes")
+	errAES256KeySize = errors.New("invalid AES-SIV-256 key size: must be 64 bytes")
+)
+
+// NewAES returns an AES-SIV AEAD with the given key, which must be 32, 48 or
+// 64 bytes, giving AES-SIV-128, AES-SIV-192 or AES-SIV-256 respectively.
+func NewAES(key []byte, opts ...Option) (*SIV, error) {
+	switch len(key) {
+	case 32, 48, 64:
+	default:
+		return nil, errAESKeySize
+	}
+
+	return New(key, aes.NewCipher, opts...)
+}
+
+// NewAES128 is like NewAES, but only accepts the 32-byte keys of AES-SIV-128.
+func NewAES128(key []byte, opts ...Option) (*SIV, error) {
+	if len(key) != 32 {
+		return nil, errAES128KeySize
+	}
+
+	return New(key, aes.NewCipher, opts...)
+}
+
+// NewAES256 is like NewAES, but only accepts the 64-byte keys of AES-SIV-256,
+// so that a key of the wrong length cannot silently select a weaker variant.
+func NewAES256(key []byte, opts ...Option) (*SIV, error) {
+	if len(key) != 64 {
+		return nil, errAES256KeySize
+	}
+
+	return New(key, aes.NewCipher, opts...)
+}
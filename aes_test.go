@@ -0,0 +1,77 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestNewAES(t *testing.T) {
+	// https://tools.ietf.org/html/rfc5297#appendix-A.1
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	expected, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	for name, newAES := range map[string]func([]byte, ...Option) (*SIV, error){
+		"NewAES":    NewAES,
+		"NewAES128": NewAES128,
+	} {
+		aead, err := newAES(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual := aead.Seal(nil, nil, plaintext, data); !bytes.Equal(actual, expected) {
+			t.Errorf("%s ciphertext was %x, but expected %x", name, actual, expected)
+		}
+	}
+}
+
+func TestNewAESKeySizes(t *testing.T) {
+	for _, v := range []struct {
+		name    string
+		new     func([]byte, ...Option) (*SIV, error)
+		allowed map[int]bool
+		err     error
+	}{
+		{"NewAES", NewAES, map[int]bool{32: true, 48: true, 64: true}, errAESKeySize},
+		{"NewAES128", NewAES128, map[int]bool{32: true}, errAES128KeySize},
+		{"NewAES256", NewAES256, map[int]bool{64: true}, errAES256KeySize},
+	} {
+		for n := 0; n <= 80; n++ {
+			_, err := v.new(make([]byte, n))
+
+			if v.allowed[n] && err != nil {
+				t.Errorf("%s with a %d-byte key returned %v", v.name, n, err)
+			}
+
+			if !v.allowed[n] && err != v.err {
+				t.Errorf("Error from %s for a %d-byte key was %v, but expected %v", v.name, n, err, v.err)
+			}
+		}
+	}
+}
+
+func TestNewAES256(t *testing.T) {
+	key := make([]byte, 64)
+	for i := range key {
+		key[i] = byte(i)
+	}
+
+	a, err := NewAES256(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte("hello, world")
+	if x, y := a.Seal(nil, nil, plaintext, nil), b.Seal(nil, nil, plaintext, nil); !bytes.Equal(x, y) {
+		t.Errorf("NewAES256 ciphertext was %x, but New gave %x", x, y)
+	}
+}
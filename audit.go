@@ -0,0 +1,43 @@
+package siv
+
+import (
+	"bytes"
+)
+
+// OpenVerbose is like Open with no nonce, but if the ciphertext fails to
+// authenticate it also returns the tag recomputed from the decrypted plaintext
+// and the tag that was received, so that key or associated data mismatches can
+// be diagnosed. Authentication still uses a constant-time comparison; the tags
+// are only recomputed once it has failed.
+//
+// The recomputed tag is derived from the key and an unauthenticated plaintext,
+// so OpenVerbose is intended for audit logging only and should not be used in
+// place of Open in normal operation.
+func (s *SIV) OpenVerbose(ciphertext, data []byte) (plaintext, expectedTag, gotTag []byte, err error) {
+	plaintext, err = s.open(nil, ciphertext, nil, data)
+	if err != ErrAuthentication || !s.Valid(ciphertext) {
+		return plaintext, nil, nil, err
+	}
+
+	sc := s.getScratch()
+	defer s.putScratch(sc)
+	s.bind(sc)
+
+	ciphertext = ciphertext[len(s.magic):]
+	gotTag = append([]byte(nil), ciphertext[:s.tagSize()]...)
+
+	b := append([]byte(nil), ciphertext[s.tagSize():]...)
+	s.xorKeyStream(sc, b, gotTag)
+	expectedTag = append([]byte(nil), sc.s2v(s.magic, [][]byte{data}, nil, b)...)
+
+	for i := range b {
+		b[i] = 0
+	}
+
+	if bytes.Equal(expectedTag, gotTag) {
+		// the AEAD must have been reset or closed concurrently
+		return nil, nil, nil, err
+	}
+
+	return nil, expectedTag, gotTag, err
+}
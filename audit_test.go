@@ -0,0 +1,77 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestOpenVerbose(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	ciphertext, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, expectedTag, gotTag, err := aead.OpenVerbose(ciphertext, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+
+	if expectedTag != nil || gotTag != nil {
+		t.Errorf("Tags %x and %x were returned for an authentic ciphertext", expectedTag, gotTag)
+	}
+
+	tampered := append([]byte(nil), ciphertext...)
+	tampered[len(tampered)-1] ^= 1
+
+	actual, expectedTag, gotTag, err = aead.OpenVerbose(tampered, data)
+	if err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v (plaintext %x)", err, ErrAuthentication, actual)
+	}
+
+	if !bytes.Equal(gotTag, ciphertext[:16]) {
+		t.Errorf("Received tag was %x, but expected %x", gotTag, ciphertext[:16])
+	}
+
+	if len(expectedTag) != 16 || bytes.Equal(expectedTag, gotTag) {
+		t.Errorf("Recomputed tag was %x, but expected a different 16-byte tag from %x", expectedTag, gotTag)
+	}
+}
+
+func TestOpenVerboseWrongData(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, []byte("right"))
+
+	_, expectedTag, gotTag, err := aead.OpenVerbose(ciphertext, []byte("wrong"))
+	if err != ErrAuthentication {
+		t.Fatalf("Error was %v, but expected %v", err, ErrAuthentication)
+	}
+
+	// with only the associated data wrong, the recomputed tag is that of the
+	// original plaintext under the wrong associated data
+	expected := aead.Seal(nil, nil, plaintext, []byte("wrong"))[:16]
+	if !bytes.Equal(expectedTag, expected) {
+		t.Errorf("Recomputed tag was %x, but expected %x", expectedTag, expected)
+	}
+
+	if !bytes.Equal(gotTag, ciphertext[:16]) {
+		t.Errorf("Received tag was %x, but expected %x", gotTag, ciphertext[:16])
+	}
+}
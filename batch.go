@@ -0,0 +1,77 @@
+package siv
+
+// SealBatch seals each of plaintexts with the same associated data, as Seal
+// does with no nonce. The ciphertexts share a single allocation and the
+// scratch space is reused across the batch, but each is an independent SIV
+// ciphertext which can be opened with Open.
+func (s *SIV) SealBatch(plaintexts [][]byte, data []byte) [][]byte {
+	sc := s.getScratch()
+	defer s.putScratch(sc)
+
+	n := 0
+	for _, plaintext := range plaintexts {
+		n += s.Overhead() + len(plaintext)
+	}
+
+	buf := make([]byte, n)
+	ad := [][]byte{data}
+	ciphertexts := make([][]byte, len(plaintexts))
+
+	for i, plaintext := range plaintexts {
+		n := s.Overhead() + len(plaintext)
+		ciphertext, err := s.sealWith(sc, buf[:0:n], plaintext, nil, ad)
+		if err != nil {
+			panic("siv: " + err.Error())
+		}
+
+		ciphertexts[i] = ciphertext
+		buf = buf[n:]
+	}
+
+	return ciphertexts
+}
+
+// OpenBatch opens each of ciphertexts with the same associated data, as Open
+// does with no nonce. If any ciphertext fails to open, OpenBatch returns its
+// error and no plaintexts.
+func (s *SIV) OpenBatch(ciphertexts [][]byte, data []byte) ([][]byte, error) {
+	if s.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	sc := s.getScratch()
+	defer s.putScratch(sc)
+
+	n := 0
+	for _, ciphertext := range ciphertexts {
+		if !s.Valid(ciphertext) {
+			return nil, ErrAuthentication
+		}
+		if s.tooLong(ciphertext) {
+			return nil, errMaxOpenLen
+		}
+		n += len(ciphertext) - s.Overhead()
+	}
+
+	all := make([]byte, n)
+	buf := all
+	ad := [][]byte{data}
+	plaintexts := make([][]byte, len(ciphertexts))
+
+	for i, ciphertext := range ciphertexts {
+		n := len(ciphertext) - s.Overhead()
+
+		plaintext, err := s.openWith(sc, buf[:0:n], ciphertext, nil, ad)
+		if err != nil {
+			for i := range all {
+				all[i] = 0
+			}
+			return nil, err
+		}
+
+		plaintexts[i] = plaintext
+		buf = buf[n:]
+	}
+
+	return plaintexts, nil
+}
@@ -0,0 +1,127 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSealBatch(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var plaintexts [][]byte
+	for n := 0; n <= 40; n++ {
+		plaintexts = append(plaintexts, bytes.Repeat([]byte{byte(n)}, n))
+	}
+
+	ciphertexts := aead.SealBatch(plaintexts, data)
+	if len(ciphertexts) != len(plaintexts) {
+		t.Fatalf("SealBatch returned %d ciphertexts, but expected %d", len(ciphertexts), len(plaintexts))
+	}
+
+	for i, ciphertext := range ciphertexts {
+		if expected := aead.Seal(nil, nil, plaintexts[i], data); !bytes.Equal(ciphertext, expected) {
+			t.Errorf("Ciphertext %d was %x, but expected %x", i, ciphertext, expected)
+		}
+
+		actual, err := aead.Open(nil, nil, ciphertext, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual, plaintexts[i]) {
+			t.Errorf("Plaintext %d was %x, but expected %x", i, actual, plaintexts[i])
+		}
+	}
+
+	// appending to one ciphertext must not overwrite the next
+	_ = append(ciphertexts[0], 0xff)
+	if expected := aead.Seal(nil, nil, plaintexts[1], data); !bytes.Equal(ciphertexts[1], expected) {
+		t.Errorf("Ciphertext 1 was overwritten: %x", ciphertexts[1])
+	}
+
+	actual, err := aead.OpenBatch(ciphertexts, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range actual {
+		if !bytes.Equal(actual[i], plaintexts[i]) {
+			t.Errorf("Plaintext %d was %x, but expected %x", i, actual[i], plaintexts[i])
+		}
+	}
+}
+
+func TestOpenBatchFailure(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertexts := aead.SealBatch([][]byte{[]byte("one"), []byte("two"), []byte("three")}, data)
+	ciphertexts[2][len(ciphertexts[2])-1] ^= 1
+
+	if actual, err := aead.OpenBatch(ciphertexts, data); err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v (plaintexts %x)", err, ErrAuthentication, actual)
+	}
+
+	if actual, err := aead.OpenBatch([][]byte{ciphertexts[0], ciphertexts[0][:15]}, data); err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v (plaintexts %x)", err, ErrAuthentication, actual)
+	}
+}
+
+func BenchmarkSealIndividual(b *testing.B) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintexts := make([][]byte, 10000)
+	for i := range plaintexts {
+		plaintexts[i] = make([]byte, 32)
+	}
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.SetBytes(32 * 10000)
+
+	for i := 0; i < b.N; i++ {
+		for _, plaintext := range plaintexts {
+			aead.Seal(nil, nil, plaintext, data)
+		}
+	}
+}
+
+func BenchmarkSealBatch(b *testing.B) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintexts := make([][]byte, 10000)
+	for i := range plaintexts {
+		plaintexts[i] = make([]byte, 32)
+	}
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.SetBytes(32 * 10000)
+
+	for i := 0; i < b.N; i++ {
+		aead.SealBatch(plaintexts, data)
+	}
+}
@@ -0,0 +1,80 @@
+package siv
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const channelSeqSize = 8
+
+var (
+	errReplay = errors.New("message replayed or out of order")
+	errGap    = errors.New("too many messages skipped")
+)
+
+// A Channel seals and opens a sequence of messages between two parties,
+// binding each to a sequence number so that a replayed, reordered or
+// duplicated message is rejected. Each message is the sequence number, as an
+// 8-byte big-endian integer, followed by a ciphertext sealed with the given
+// associated data and the sequence number as separate components. Each
+// direction of a conversation needs its own Channel at each end, as the
+// sequence numbers are independent. A Channel is not safe for concurrent use.
+type Channel struct {
+	s        *SIV
+	maxGap   uint64
+	next     uint64 // sequence number of the next message sealed
+	last     uint64 // sequence number of the last message opened
+	received bool
+}
+
+// NewChannel returns a new Channel using the AEAD s. Open rejects a message
+// which skips more than maxGap sequence numbers after the last one it opened,
+// so with a maxGap of 0 no message may be lost; use math.MaxUint64 for no
+// limit.
+func (s *SIV) NewChannel(maxGap uint64) *Channel {
+	return &Channel{s: s, maxGap: maxGap}
+}
+
+// Seal seals plaintext as the next message in the sequence.
+func (c *Channel) Seal(plaintext, data []byte) []byte {
+	out := make([]byte, channelSeqSize, channelSeqSize+c.s.Overhead()+len(plaintext))
+	binary.BigEndian.PutUint64(out, c.next)
+
+	out = c.s.mustSeal(out, plaintext, nil, data, out[:channelSeqSize])
+	c.next++
+
+	return out
+}
+
+// Open opens a message sealed by the other end's Seal. It returns an error if
+// the message is not authentic, or its sequence number is not greater than
+// that of the last message opened or skips too many, in which case the
+// Channel is unchanged.
+func (c *Channel) Open(message, data []byte) ([]byte, error) {
+	if len(message) < channelSeqSize {
+		return nil, ErrAuthentication
+	}
+
+	seq := binary.BigEndian.Uint64(message)
+
+	// the first message may skip from before sequence number 0
+	if c.received {
+		if seq <= c.last {
+			return nil, errReplay
+		}
+
+		if seq-c.last-1 > c.maxGap {
+			return nil, errGap
+		}
+	} else if seq > c.maxGap {
+		return nil, errGap
+	}
+
+	plaintext, err := c.s.open(nil, message[channelSeqSize:], nil, data, message[:channelSeqSize])
+	if err != nil {
+		return nil, err
+	}
+
+	c.last, c.received = seq, true
+	return plaintext, nil
+}
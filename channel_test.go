@@ -0,0 +1,97 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func newTestChannels(t *testing.T, maxGap uint64) (*Channel, *Channel) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return aead.NewChannel(maxGap), aead.NewChannel(maxGap)
+}
+
+func TestChannel(t *testing.T) {
+	sender, receiver := newTestChannels(t, 0)
+	data := []byte("channel data")
+
+	for i := 0; i < 10; i++ {
+		plaintext := []byte(fmt.Sprintf("message %d", i))
+
+		actual, err := receiver.Open(sender.Seal(plaintext, data), data)
+		if err != nil {
+			t.Fatalf("Open of message %d failed: %v", i, err)
+		}
+
+		if !bytes.Equal(actual, plaintext) {
+			t.Errorf("Plaintext was %q, but expected %q", actual, plaintext)
+		}
+	}
+}
+
+func TestChannelReplay(t *testing.T) {
+	sender, receiver := newTestChannels(t, math.MaxUint64)
+
+	first := sender.Seal([]byte("first"), nil)
+	second := sender.Seal([]byte("second"), nil)
+
+	if _, err := receiver.Open(second, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, message := range map[string][]byte{"replayed": second, "reordered": first} {
+		if actual, err := receiver.Open(message, nil); err != errReplay {
+			t.Errorf("Error for a %s message was %v, but expected %v (plaintext %q)", name, err, errReplay, actual)
+		}
+	}
+
+	// the sequence number is authenticated, so cannot be advanced
+	third := sender.Seal([]byte("third"), nil)
+	forged := append([]byte(nil), first...)
+	forged[channelSeqSize-1] = 5
+
+	if actual, err := receiver.Open(forged, nil); err != ErrAuthentication {
+		t.Errorf("Error for a forged sequence number was %v, but expected %v (plaintext %q)", err, ErrAuthentication, actual)
+	}
+
+	// a rejected message leaves the channel unchanged
+	if _, err := receiver.Open(third, nil); err != nil {
+		t.Errorf("Open after rejected messages failed: %v", err)
+	}
+
+	if actual, err := receiver.Open(third[:channelSeqSize-1], nil); err != ErrAuthentication {
+		t.Errorf("Error for a truncated message was %v, but expected %v (plaintext %q)", err, ErrAuthentication, actual)
+	}
+}
+
+func TestChannelGap(t *testing.T) {
+	for _, maxGap := range []uint64{0, 1, 3} {
+		sender, receiver := newTestChannels(t, maxGap)
+
+		// a lost message, then one skipping exactly maxGap sequence numbers
+		for i := uint64(0); i < maxGap; i++ {
+			sender.Seal(nil, nil)
+		}
+
+		if _, err := receiver.Open(sender.Seal(nil, nil), nil); err != nil {
+			t.Errorf("Open skipping %d messages with a max gap of %d failed: %v", maxGap, maxGap, err)
+		}
+
+		for i := uint64(0); i <= maxGap; i++ {
+			sender.Seal(nil, nil)
+		}
+
+		if _, err := receiver.Open(sender.Seal(nil, nil), nil); err != errGap {
+			t.Errorf("Error skipping %d messages with a max gap of %d was %v, but expected %v", maxGap+1, maxGap, err, errGap)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package siv
+
+import (
+	"io"
+)
+
+// defaultChunkSize is the size of the chunks SealTo encrypts and writes at a
+// time unless WithChunkSize is given.
+const defaultChunkSize = 64 * 1024
+
+// SealTo is like Seal with no nonce, but writes the ciphertext to w rather than
+// returning it. The plaintext is encrypted and written in chunks of at most
+// the size configured with WithChunkSize, so only one chunk of ciphertext is
+// held in memory at a time and no single call to the CTR stream runs for the
+// whole message. The output is identical to that of Seal, and is a single
+// message with a single synthetic IV, unlike StreamSeal. If w returns an error,
+// SealTo returns it and w may have received part of the ciphertext.
+func (s *SIV) SealTo(w io.Writer, plaintext, data []byte) error {
+	if err := s.checkNonce(nil); err != nil {
+		return err
+	}
+
+	if s.closed.Load() {
+		return ErrClosed
+	}
+
+	sc := s.getScratch()
+	defer s.putScratch(sc)
+	s.bind(sc)
+
+	v := sc.s2v(s.magic, [][]byte{data}, nil, plaintext)
+
+	size := s.chunkSize
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+	if size > len(plaintext) {
+		size = len(plaintext)
+	}
+
+	buf := make([]byte, s.Overhead()+size)
+	n := copy(buf, s.magic)
+	n += copy(buf[n:], v)
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+
+	ctr := s.stream(v)
+	for len(plaintext) > 0 {
+		chunk := buf[:size]
+		if len(plaintext) < size {
+			chunk = chunk[:len(plaintext)]
+		}
+
+		ctr.XORKeyStream(chunk, plaintext[:len(chunk)])
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+
+		plaintext = plaintext[len(chunk):]
+	}
+
+	return nil
+}
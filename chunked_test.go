@@ -0,0 +1,109 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestSealTo(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	plaintext := make([]byte, 200*1024+7)
+	for i := range plaintext {
+		plaintext[i] = byte(i % 251)
+	}
+
+	for _, size := range []int{0, 1, 16, 1000, 64 * 1024, 1 << 20} {
+		aead, err := New(key, aes.NewCipher, WithChunkSize(size), WithMagic([]byte("SIV1")))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, n := range []int{0, 14, 16, 17, 4096, len(plaintext)} {
+			expected := aead.Seal(nil, nil, plaintext[:n], data)
+
+			buf := new(bytes.Buffer)
+			if err := aead.SealTo(buf, plaintext[:n], data); err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(buf.Bytes(), expected) {
+				t.Errorf("Chunked ciphertext of %d bytes in chunks of %d did not match Seal", n, size)
+			}
+		}
+	}
+}
+
+func TestSealToWriteError(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	expected := errors.New("write failed")
+
+	aead, err := New(key, aes.NewCipher, WithChunkSize(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for n := 0; n < 3; n++ {
+		w := &failingWriter{n: n, err: expected}
+		if err := aead.SealTo(w, make([]byte, 64), nil); err != expected {
+			t.Errorf("Error after %d writes was %v, but expected %v", n, err, expected)
+		}
+	}
+}
+
+// failingWriter accepts n writes and then returns err.
+type failingWriter struct {
+	n   int
+	err error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.n == 0 {
+		return 0, w.err
+	}
+	w.n--
+	return len(p), nil
+}
+
+func BenchmarkSealOneShot(b *testing.B) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext := make([]byte, 4<<20)
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(plaintext)))
+
+	for i := 0; i < b.N; i++ {
+		_, _ = io.Discard.Write(aead.Seal(nil, nil, plaintext, nil))
+	}
+}
+
+func BenchmarkSealChunked(b *testing.B) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext := make([]byte, 4<<20)
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(plaintext)))
+
+	for i := 0; i < b.N; i++ {
+		if err := aead.SealTo(io.Discard, plaintext, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+// Command sivcrypt encrypts and decrypts data with the AEADs of package siv.
+//
+// Usage:
+//
+//	sivcrypt [-d] -key-file path|-key-hex hex|-key-base64 b64 [flags]
+//
+// The input is read from -in, or standard input, and the output written to
+// -out, or standard output. Each -ad flag adds an associated data component,
+// in order. With -tag, the tag is written to or read from a separate file
+// rather than being part of the ciphertext.
+//
+// The modes are cmac (SIV-CMAC, the default), pmac (PMAC-SIV) and gcm-siv
+// (AES-GCM-SIV). For the SIV modes -nonce is optional and authenticated as the
+// final associated data component; gcm-siv requires a 12-byte nonce and
+// accepts at most one -ad.
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	siv "github.com/stripe/siv-go"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "sivcrypt:", err)
+		os.Exit(1)
+	}
+}
+
+// adFlags collects repeated -ad flags.
+type adFlags [][]byte
+
+func (a *adFlags) String() string {
+	return fmt.Sprint(*a)
+}
+
+func (a *adFlags) Set(s string) error {
+	*a = append(*a, []byte(s))
+	return nil
+}
+
+type options struct {
+	decrypt   bool
+	mode      string
+	key       []byte
+	ad        [][]byte
+	nonce     []byte
+	tag       string
+	in, out   string
+	keyFile   string
+	keyHex    string
+	keyBase64 string
+	nonceHex  string
+}
+
+func parse(args []string) (*options, error) {
+	var o options
+	var ad adFlags
+
+	fs := flag.NewFlagSet("sivcrypt", flag.ContinueOnError)
+	fs.BoolVar(&o.decrypt, "d", false, "decrypt rather than encrypt")
+	fs.StringVar(&o.mode, "mode", "cmac", "the AEAD: cmac, pmac or gcm-siv")
+	fs.StringVar(&o.keyFile, "key-file", "", "read the raw key from `path`")
+	fs.StringVar(&o.keyHex, "key-hex", "", "the key, hex encoded")
+	fs.StringVar(&o.keyBase64, "key-base64", "", "the key, base64 encoded")
+	fs.Var(&ad, "ad", "an associated data component; may be repeated")
+	fs.StringVar(&o.nonceHex, "nonce", "", "the nonce, hex encoded")
+	fs.StringVar(&o.tag, "tag", "", "write or read the tag at `path`, detached from the ciphertext")
+	fs.StringVar(&o.in, "in", "", "read the input from `path` rather than standard input")
+	fs.StringVar(&o.out, "out", "", "write the output to `path` rather than standard output")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	o.ad = ad
+
+	var err error
+	if o.key, err = o.readKey(); err != nil {
+		return nil, err
+	}
+
+	if o.nonceHex != "" {
+		if o.nonce, err = hex.DecodeString(o.nonceHex); err != nil {
+			return nil, fmt.Errorf("invalid nonce: %v", err)
+		}
+	}
+
+	return &o, nil
+}
+
+func (o *options) readKey() ([]byte, error) {
+	n := 0
+	for _, v := range []string{o.keyFile, o.keyHex, o.keyBase64} {
+		if v != "" {
+			n++
+		}
+	}
+	if n != 1 {
+		return nil, errors.New("exactly one of -key-file, -key-hex and -key-base64 is required")
+	}
+
+	switch {
+	case o.keyFile != "":
+		return os.ReadFile(o.keyFile)
+	case o.keyHex != "":
+		key, err := hex.DecodeString(strings.TrimSpace(o.keyHex))
+		if err != nil {
+			return nil, fmt.Errorf("invalid key: %v", err)
+		}
+		return key, nil
+	default:
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(o.keyBase64))
+		if err != nil {
+			return nil, fmt.Errorf("invalid key: %v", err)
+		}
+		return key, nil
+	}
+}
+
+// aead is the subset of the package's AEADs used here.
+type aead interface {
+	seal(plaintext []byte) []byte
+	open(ciphertext []byte) ([]byte, error)
+	split(ciphertext []byte) (tag, body []byte, err error)
+	join(tag, body []byte) ([]byte, error)
+}
+
+func (o *options) aead() (aead, error) {
+	switch o.mode {
+	case "cmac", "pmac":
+		newAEAD := siv.New
+		if o.mode == "pmac" {
+			newAEAD = siv.NewPMACSIV
+		}
+
+		s, err := newAEAD(o.key, aes.NewCipher)
+		if err != nil {
+			return nil, err
+		}
+
+		ad := o.ad
+		if o.nonce != nil {
+			ad = append(ad, o.nonce)
+		}
+		return &sivAEAD{s: s, ad: ad}, nil
+
+	case "gcm-siv":
+		if len(o.ad) > 1 {
+			return nil, errors.New("gcm-siv accepts at most one -ad")
+		}
+
+		a, err := siv.NewGCMSIV(o.key)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(o.nonce) != a.NonceSize() {
+			return nil, fmt.Errorf("gcm-siv requires a %d-byte -nonce", a.NonceSize())
+		}
+
+		g := &gcmAEAD{a: a, nonce: o.nonce}
+		if len(o.ad) == 1 {
+			g.ad = o.ad[0]
+		}
+		return g, nil
+	}
+
+	return nil, fmt.Errorf("unknown mode %q", o.mode)
+}
+
+type sivAEAD struct {
+	s  *siv.SIV
+	ad [][]byte
+}
+
+func (a *sivAEAD) seal(plaintext []byte) []byte {
+	return a.s.SealMulti(nil, plaintext, a.ad...)
+}
+
+func (a *sivAEAD) open(ciphertext []byte) ([]byte, error) {
+	return a.s.OpenMulti(nil, ciphertext, a.ad...)
+}
+
+func (a *sivAEAD) split(ciphertext []byte) ([]byte, []byte, error) {
+	return a.s.SplitTag(ciphertext)
+}
+
+func (a *sivAEAD) join(tag, body []byte) ([]byte, error) {
+	if len(tag) != a.s.TagSize() {
+		return nil, siv.ErrAuthentication
+	}
+	return a.s.JoinTag(tag, body), nil
+}
+
+type gcmAEAD struct {
+	a         cipher.AEAD
+	nonce, ad []byte
+}
+
+func (g *gcmAEAD) seal(plaintext []byte) []byte {
+	return g.a.Seal(nil, g.nonce, plaintext, g.ad)
+}
+
+func (g *gcmAEAD) open(ciphertext []byte) ([]byte, error) {
+	return g.a.Open(nil, g.nonce, ciphertext, g.ad)
+}
+
+// split separates the tag, which AES-GCM-SIV appends rather than prepends.
+func (g *gcmAEAD) split(ciphertext []byte) ([]byte, []byte, error) {
+	n := len(ciphertext) - g.a.Overhead()
+	if n < 0 {
+		return nil, nil, siv.ErrAuthentication
+	}
+	return ciphertext[n:], ciphertext[:n], nil
+}
+
+func (g *gcmAEAD) join(tag, body []byte) ([]byte, error) {
+	if len(tag) != g.a.Overhead() {
+		return nil, siv.ErrAuthentication
+	}
+	return append(append([]byte(nil), body...), tag...), nil
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	o, err := parse(args)
+	if err != nil {
+		return err
+	}
+
+	a, err := o.aead()
+	if err != nil {
+		return err
+	}
+
+	input, err := readInput(o.in, stdin)
+	if err != nil {
+		return err
+	}
+
+	var output []byte
+	if o.decrypt {
+		if o.tag != "" {
+			tag, err := os.ReadFile(o.tag)
+			if err != nil {
+				return err
+			}
+
+			if input, err = a.join(tag, input); err != nil {
+				return err
+			}
+		}
+
+		if output, err = a.open(input); err != nil {
+			return err
+		}
+	} else {
+		output = a.seal(input)
+
+		if o.tag != "" {
+			tag, body, err := a.split(output)
+			if err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(o.tag, tag, 0o600); err != nil {
+				return err
+			}
+			output = body
+		}
+	}
+
+	return writeOutput(o.out, stdout, output)
+}
+
+func readInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
+
+func writeOutput(path string, stdout io.Writer, b []byte) error {
+	if path == "" {
+		_, err := io.Copy(stdout, bytes.NewReader(b))
+		return err
+	}
+	return os.WriteFile(path, b, 0o600)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRFC5297(t *testing.T) {
+	// https://tools.ietf.org/html/rfc5297#appendix-A.1
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	expected, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	key := "-key-hex=fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff"
+
+	out := new(bytes.Buffer)
+	if err := run([]string{key, "-ad", string(data)}, bytes.NewReader(plaintext), out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(out.Bytes(), expected) {
+		t.Errorf("Ciphertext was %x, but expected %x", out.Bytes(), expected)
+	}
+
+	decrypted := new(bytes.Buffer)
+	if err := run([]string{"-d", key, "-ad", string(data)}, bytes.NewReader(expected), decrypted); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(decrypted.Bytes(), plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", decrypted.Bytes(), plaintext)
+	}
+
+	if err := run([]string{"-d", key, "-ad", "wrong"}, bytes.NewReader(expected), new(bytes.Buffer)); err == nil {
+		t.Error("Decrypted with the wrong associated data")
+	}
+}
+
+func TestModes(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, bytes.Repeat([]byte{1}, 32), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte("hello, world")
+
+	for _, args := range [][]string{
+		{"-mode=cmac", "-key-file", keyFile, "-ad", "a", "-ad", "b"},
+		{"-mode=pmac", "-key-base64", "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=", "-nonce", "00"},
+		{"-mode=gcm-siv", "-key-hex", "01010101010101010101010101010101", "-nonce", "000000000000000000000000"},
+		{"-key-file", keyFile, "-tag", filepath.Join(dir, "tag")},
+		{"-mode=gcm-siv", "-key-hex", "01010101010101010101010101010101", "-nonce", "000000000000000000000000", "-tag", filepath.Join(dir, "gcm-tag")},
+	} {
+		ciphertext := new(bytes.Buffer)
+		if err := run(args, bytes.NewReader(plaintext), ciphertext); err != nil {
+			t.Fatalf("Encrypting with %q: %v", args, err)
+		}
+
+		decrypted := new(bytes.Buffer)
+		if err := run(append([]string{"-d"}, args...), ciphertext, decrypted); err != nil {
+			t.Fatalf("Decrypting with %q: %v", args, err)
+		}
+
+		if !bytes.Equal(decrypted.Bytes(), plaintext) {
+			t.Errorf("Plaintext with %q was %q, but expected %q", args, decrypted.Bytes(), plaintext)
+		}
+	}
+}
+
+func TestInvalidArguments(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"-key-hex", "00", "-key-base64", "AA=="},
+		{"-key-hex", "zz"},
+		{"-key-hex", "0101010101010101010101010101010101010101010101010101010101010101", "-mode", "ecb"},
+		{"-key-hex", "01010101010101010101010101010101", "-mode", "gcm-siv"},
+		{"-key-hex", "0101010101010101010101010101010101010101010101010101010101010101", "extra"},
+	} {
+		if err := run(args, bytes.NewReader(nil), new(bytes.Buffer)); err == nil {
+			t.Errorf("Arguments %q were accepted", args)
+		}
+	}
+}
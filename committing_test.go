@@ -0,0 +1,78 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestCommitting(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := NewCommitting(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := aead.Overhead(); n != 16+32 {
+		t.Errorf("Overhead was %d, but expected %d", n, 16+32)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+	if len(ciphertext) != len(plaintext)+aead.Overhead() {
+		t.Errorf("Ciphertext was %d bytes, but expected %d", len(ciphertext), len(plaintext)+aead.Overhead())
+	}
+
+	// the ciphertext is that of plain SIV, followed by the commitment
+	if expected := plain.Seal(nil, nil, plaintext, data); !bytes.Equal(ciphertext[:len(expected)], expected) {
+		t.Errorf("Ciphertext began %x, but expected %x", ciphertext[:len(expected)], expected)
+	}
+
+	actual, err := aead.Open(nil, nil, ciphertext, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+
+	for i := range ciphertext {
+		tampered := append([]byte(nil), ciphertext...)
+		tampered[i] ^= 1
+
+		if _, err := aead.Open(nil, nil, tampered, data); err != ErrAuthentication {
+			t.Errorf("Error for a ciphertext tampered at byte %d was %v, but expected %v", i, err, ErrAuthentication)
+		}
+	}
+
+	for _, n := range []int{0, 16, 47} {
+		if _, err := aead.Open(nil, nil, ciphertext[:n], data); err != ErrAuthentication {
+			t.Errorf("Error for a %d-byte ciphertext was %v, but expected %v", n, err, ErrAuthentication)
+		}
+	}
+}
+
+func TestCommittingOtherKey(t *testing.T) {
+	a, _ := NewCommitting(bytes.Repeat([]byte{1}, 32), aes.NewCipher)
+	b, _ := NewCommitting(bytes.Repeat([]byte{2}, 32), aes.NewCipher)
+
+	ciphertext := a.Seal(nil, nil, []byte("hello, world"), nil)
+
+	if _, err := b.Open(nil, nil, ciphertext, nil); err != ErrAuthentication {
+		t.Errorf("Error with another key was %v, but expected %v", err, ErrAuthentication)
+	}
+
+	_ = a.Close()
+	if _, err := a.Open(nil, nil, ciphertext, nil); err != ErrClosed {
+		t.Errorf("Error after Close was %v, but expected %v", err, ErrClosed)
+	}
+}
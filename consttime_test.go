@@ -0,0 +1,123 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"flag"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stripe/siv-go/internal/cmac"
+)
+
+var dudect = flag.Bool("dudect", false, "run the timing-leakage tests")
+
+func TestDblReference(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, size := range []int{8, 16} {
+		n := uint(8 * size)
+		poly := new(big.Int).Lsh(big.NewInt(1), n)
+		poly.Or(poly, big.NewInt(int64(cmac.Rb(size))))
+
+		for i := 0; i < 1000; i++ {
+			b := make([]byte, size)
+			r.Read(b)
+
+			// x*b, reduced by the polynomial if it overflows
+			expected := new(big.Int).Lsh(new(big.Int).SetBytes(b), 1)
+			if expected.Bit(int(n)) == 1 {
+				expected.Xor(expected, poly)
+			}
+
+			in := append([]byte(nil), b...)
+			cmac.Dbl(b)
+			if actual := new(big.Int).SetBytes(b); actual.Cmp(expected) != 0 {
+				t.Fatalf("dbl(%x) was %x, but expected %x", in, b, expected)
+			}
+
+			halve(b)
+			if !bytes.Equal(b, in) {
+				t.Fatalf("halve(dbl(%x)) was %x, but expected %x", in, b, in)
+			}
+		}
+	}
+}
+
+// leakage measures f on two classes of input in the manner of dudect, and
+// returns Welch's t-statistic for the difference in their timings. Values of
+// |t| above about 10 indicate that the time taken depends on the class.
+func leakage(f func(class int)) float64 {
+	const samples, batch = 20000, 64
+
+	r := rand.New(rand.NewSource(1))
+	var n, mean, m2 [2]float64
+
+	for i := 0; i < samples; i++ {
+		class := r.Intn(2)
+
+		start := time.Now()
+		for j := 0; j < batch; j++ {
+			f(class)
+		}
+		d := float64(time.Since(start))
+
+		// Welford's online mean and variance
+		n[class]++
+		delta := d - mean[class]
+		mean[class] += delta / n[class]
+		m2[class] += delta * (d - mean[class])
+	}
+
+	v0, v1 := m2[0]/(n[0]-1), m2[1]/(n[1]-1)
+	return (mean[0] - mean[1]) / math.Sqrt(v0/n[0]+v1/n[1])
+}
+
+func TestConstantTime(t *testing.T) {
+	if !*dudect {
+		t.Skip("timing tests are noisy; run with -dudect")
+	}
+
+	aead, _ := New(bytes.Repeat([]byte{1}, 32), aes.NewCipher)
+	plaintext := bytes.Repeat([]byte{0xaa}, 64)
+	ciphertext := aead.Seal(nil, nil, plaintext, nil)
+
+	// forgeries which differ from the real synthetic IV in their first or
+	// their last byte
+	forged := [2][]byte{
+		append([]byte(nil), ciphertext...),
+		append([]byte(nil), ciphertext...),
+	}
+	forged[0][0] ^= 1
+	forged[1][15] ^= 1
+
+	block := [2][]byte{make([]byte, 16), make([]byte, 16)}
+	inputs := [2][]byte{make([]byte, 64), bytes.Repeat([]byte{0xff}, 64)}
+	dst := make([]byte, 0, 128)
+
+	for _, test := range []struct {
+		name string
+		f    func(class int)
+	}{
+		{"dbl", func(class int) {
+			// the top bit, which selects the reduction, set or clear
+			block[class][0] = byte(class) << 7
+			cmac.Dbl(block[class])
+		}},
+		{"Open", func(class int) {
+			_, _ = aead.Open(dst[:0], nil, forged[class], nil)
+		}},
+		{"Seal", func(class int) {
+			aead.Seal(dst[:0], nil, inputs[class], nil)
+		}},
+	} {
+		if v := leakage(test.f); math.Abs(v) > 10 {
+			t.Errorf("%s t-statistic was %.1f, suggesting a timing leak", test.name, v)
+		} else {
+			t.Logf("%s t-statistic was %.1f", test.name, v)
+		}
+	}
+}
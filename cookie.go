@@ -0,0 +1,71 @@
+package siv
+
+import (
+	"encoding/base64"
+	"errors"
+)
+
+// maxCookieSize is the largest encoded value a CookieSealer will produce or
+// accept, the minimum per-cookie size browsers are required to support by
+// RFC 6265, less a margin for the name and attributes.
+const maxCookieSize = 4000
+
+var (
+	errCookieSize = errors.New("cookie too large")
+)
+
+// A CookieSealer seals values for use in HTTP cookies. Each value is sealed
+// with the name of its cookie as associated data, so that it fails to open if
+// it is moved to a cookie with a different name, and is encoded with unpadded
+// URL-safe base64, which needs no further escaping in a cookie.
+type CookieSealer struct {
+	s *SIV
+}
+
+// NewCookieSealer returns a CookieSealer using the AEAD s.
+func (s *SIV) NewCookieSealer() *CookieSealer {
+	return &CookieSealer{s: s}
+}
+
+// Seal seals value for the cookie with the given name. It returns an error if
+// the encoded result would be longer than browsers can be relied upon to
+// store.
+func (c *CookieSealer) Seal(name string, value []byte) (string, error) {
+	if base64.RawURLEncoding.EncodedLen(c.s.Overhead()+len(value)) > maxCookieSize {
+		return "", errCookieSize
+	}
+
+	if c.s.closed.Load() {
+		return "", ErrClosed
+	}
+
+	ciphertext, err := c.s.seal(nil, value, nil, nonNil([]byte(name)))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(ciphertext), nil
+}
+
+// Open opens a value sealed by Seal for the cookie with the given name. A
+// value which is malformed, too long, or was sealed for another cookie returns
+// ErrAuthentication.
+func (c *CookieSealer) Open(name, encoded string) ([]byte, error) {
+	if len(encoded) > maxCookieSize {
+		return nil, ErrAuthentication
+	}
+
+	encoding := base64.RawURLEncoding
+	if c.s.strict {
+		// reject encodings with non-zero padding bits, so each value has
+		// only one encoding
+		encoding = encoding.Strict()
+	}
+
+	ciphertext, err := encoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrAuthentication
+	}
+
+	return c.s.open(ciphertext[:0], ciphertext, nil, nonNil([]byte(name)))
+}
@@ -0,0 +1,93 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"net/http"
+	"testing"
+)
+
+func TestCookieSealer(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	value := []byte(`{"user":42,"admin":false}`)
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookies := aead.NewCookieSealer()
+
+	encoded, err := cookies.Seal("session", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookie := &http.Cookie{Name: "session", Value: encoded}
+	if err := cookie.Valid(); err != nil {
+		t.Errorf("Sealed value %q was not a valid cookie: %v", encoded, err)
+	}
+
+	actual, err := cookies.Open("session", encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, value) {
+		t.Errorf("Value was %q, but expected %q", actual, value)
+	}
+}
+
+func TestCookieSealerTampered(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookies := aead.NewCookieSealer()
+
+	encoded, err := cookies.Seal("session", []byte("user=42"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := []byte(encoded)
+	tampered[len(tampered)/2] ^= 'A' ^ 'B'
+
+	for name, v := range map[string][2]string{
+		"tampered":       {"session", string(tampered)},
+		"moved":          {"preferences", encoded},
+		"truncated":      {"session", encoded[:10]},
+		"empty":          {"session", ""},
+		"not base64":     {"session", encoded[:len(encoded)-1] + "!"},
+		"std base64 pad": {"session", encoded + "=="},
+	} {
+		if actual, err := cookies.Open(v[0], v[1]); err != ErrAuthentication {
+			t.Errorf("Error for a %s value was %v, but expected %v (value %q)", name, err, ErrAuthentication, actual)
+		}
+	}
+}
+
+func TestCookieSealerSize(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookies := aead.NewCookieSealer()
+
+	if _, err := cookies.Seal("session", make([]byte, 2985)); err != errCookieSize {
+		t.Errorf("Error for an oversized value was %v, but expected %v", err, errCookieSize)
+	}
+
+	encoded, err := cookies.Seal("session", make([]byte, 2984))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(encoded) > maxCookieSize {
+		t.Errorf("Encoded value was %d bytes, but the limit is %d", len(encoded), maxCookieSize)
+	}
+}
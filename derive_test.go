@@ -0,0 +1,93 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestDeriveChild(t *testing.T) {
+	master, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	sessions, err := DeriveChild(master, []byte("sessions"), aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := DeriveChild(master, []byte("sessions"), aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := sessions.Seal(nil, nil, plaintext, data)
+
+	if expected := again.Seal(nil, nil, plaintext, data); !bytes.Equal(ciphertext, expected) {
+		t.Errorf("Ciphertext was %x, but expected %x", ciphertext, expected)
+	}
+
+	actual, err := again.Open(nil, nil, ciphertext, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+}
+
+func TestDeriveChildLabels(t *testing.T) {
+	master, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	parent, err := New(master, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aeads := []*SIV{parent}
+	for _, label := range [][]byte{nil, []byte("sessions"), []byte("tables"), []byte("sessions\x00")} {
+		aead, err := DeriveChild(master, label, aes.NewCipher)
+		if err != nil {
+			t.Fatal(err)
+		}
+		aeads = append(aeads, aead)
+	}
+
+	for i, a := range aeads {
+		ciphertext := a.Seal(nil, nil, plaintext, data)
+
+		for j, b := range aeads {
+			if i == j {
+				continue
+			}
+
+			if actual, err := b.Open(nil, nil, ciphertext, data); err != ErrAuthentication {
+				t.Errorf("Ciphertext from AEAD %d opened under AEAD %d: %v (plaintext %x)", i, j, err, actual)
+			}
+		}
+	}
+}
+
+func TestDeriveChildKeySize(t *testing.T) {
+	master := bytes.Repeat([]byte{1}, 64)
+	plaintext := []byte("plaintext")
+
+	for _, n := range []int{32, 48, 64} {
+		child, err := DeriveChild(master[:n], []byte("label"), aes.NewCipher)
+		if err != nil {
+			t.Fatalf("DeriveChild with a %d-byte key failed: %v", n, err)
+		}
+
+		if _, err := child.Open(nil, nil, child.Seal(nil, nil, plaintext, nil), nil); err != nil {
+			t.Errorf("Child of a %d-byte key failed to open its own ciphertext: %v", n, err)
+		}
+	}
+
+	if _, err := DeriveChild(make([]byte, 31), []byte("label"), aes.NewCipher); err != errKeySize {
+		t.Errorf("Error was %v, but expected %v", err, errKeySize)
+	}
+}
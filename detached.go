@@ -0,0 +1,106 @@
+package siv
+
+import (
+	"encoding/binary"
+)
+
+// SplitTag splits a ciphertext as returned by Seal into its synthetic IV and
+// the encrypted body, removing any magic configured with WithMagic. Both alias
+// ciphertext. It returns an error if ciphertext is too short, or lacks the
+// magic. No authentication is done.
+func (s *SIV) SplitTag(ciphertext []byte) (tag, body []byte, err error) {
+	if !s.Valid(ciphertext) {
+		return nil, nil, errCiphertextSize
+	}
+
+	tag = s.tag(ciphertext)
+	return tag, ciphertext[len(s.magic)+len(tag):], nil
+}
+
+// SealDetached is like Seal, but writes the synthetic IV to tag, which must be
+// TagSize bytes long, rather than prepending it, and appends only the
+// encrypted body to dst. Any magic configured with WithMagic is authenticated
+// but not written.
+func (s *SIV) SealDetached(dst, tag, nonce, plaintext, data []byte) []byte {
+	if err := s.checkNonce(nonce); err != nil {
+		panic("siv: " + err.Error())
+	}
+	if len(tag) != s.tagSize() {
+		panic("siv: invalid tag size")
+	}
+	if s.closed.Load() {
+		panic("siv: Seal called after Close")
+	}
+
+	ad := [][]byte{data}
+	checkOverlap(dst, len(plaintext), plaintext, [][]byte{data, tag}, nonce)
+
+	sc := s.getScratch()
+	defer s.putScratch(sc)
+	s.bind(sc)
+
+	copy(tag, sc.s2v(s.magic, ad, nonce, plaintext))
+
+	ret, out := sliceForAppend(dst, len(plaintext))
+	copy(out, plaintext)
+	s.xorKeyStream(sc, out, tag)
+
+	return ret
+}
+
+// OpenDetached opens a body and synthetic IV sealed by SealDetached, or split
+// by SplitTag, appending the plaintext to dst. A tag of the wrong size fails
+// with ErrAuthentication.
+func (s *SIV) OpenDetached(dst, nonce, ciphertext, tag, data []byte) ([]byte, error) {
+	if err := s.checkNonce(nonce); err != nil {
+		return nil, err
+	}
+	if s.closed.Load() {
+		return nil, ErrClosed
+	}
+	if len(tag) != s.tagSize() {
+		return nil, ErrAuthentication
+	}
+	if s.maxOpenLen > 0 && len(ciphertext) > s.maxOpenLen {
+		return nil, errMaxOpenLen
+	}
+
+	ad := [][]byte{data}
+	checkOverlap(dst, len(ciphertext), ciphertext, ad, nonce)
+
+	sc := s.getScratch()
+	defer s.putScratch(sc)
+
+	return s.openBody(sc, dst, tag, ciphertext, ad, nonce)
+}
+
+// TagSize returns the size of the synthetic IV, as written by SealDetached.
+func (s *SIV) TagSize() int {
+	return s.tagSize()
+}
+
+// JoinTag is the inverse of SplitTag, returning a new ciphertext in the form
+// returned by Seal. It panics if tag is not the size of a synthetic IV.
+func (s *SIV) JoinTag(tag, body []byte) []byte {
+	if len(tag) != s.tagSize() {
+		panic("siv: invalid tag size")
+	}
+
+	ciphertext := make([]byte, 0, s.Overhead()+len(body))
+	ciphertext = append(ciphertext, s.magic...)
+	ciphertext = append(ciphertext, tag...)
+	return append(ciphertext, body...)
+}
+
+// ShortTag returns the first four bytes of a ciphertext's synthetic IV as a
+// big-endian integer, for storing alongside it as a cheap integrity hint. It
+// is not a security mechanism: it is derived from the synthetic IV alone, so
+// it changes if the IV is corrupted but not if only the body is, and anyone
+// can compute it without the key. Only Open authenticates a ciphertext.
+func (s *SIV) ShortTag(ciphertext []byte) (uint32, error) {
+	if !s.Valid(ciphertext) {
+		return 0, errCiphertextSize
+	}
+
+	return binary.BigEndian.Uint32(s.tag(ciphertext)), nil
+}
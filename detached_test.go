@@ -0,0 +1,183 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSplitTag(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	ciphertext, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag, body, err := aead.SplitTag(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := ciphertext[:16]; !bytes.Equal(tag, expected) {
+		t.Errorf("Tag was %x, but expected %x", tag, expected)
+	}
+
+	if expected := ciphertext[16:]; !bytes.Equal(body, expected) {
+		t.Errorf("Body was %x, but expected %x", body, expected)
+	}
+
+	if joined := aead.JoinTag(tag, body); !bytes.Equal(joined, ciphertext) {
+		t.Errorf("Joined ciphertext was %x, but expected %x", joined, ciphertext)
+	}
+
+	for _, n := range []int{0, 1, 15} {
+		if _, _, err := aead.SplitTag(ciphertext[:n]); err != errCiphertextSize {
+			t.Errorf("Error for %d bytes was %v, but expected %v", n, err, errCiphertextSize)
+		}
+	}
+}
+
+func TestSplitTagMagic(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher, WithMagic([]byte("SIV1")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 14, 100} {
+		ciphertext := aead.Seal(nil, nil, bytes.Repeat(plaintext, n), data)
+
+		tag, body, err := aead.SplitTag(ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(tag) != 16 || len(body) != 14*n {
+			t.Errorf("Split %d bytes into a %d-byte tag and %d-byte body", len(ciphertext), len(tag), len(body))
+		}
+
+		if joined := aead.JoinTag(tag, body); !bytes.Equal(joined, ciphertext) {
+			t.Errorf("Joined ciphertext was %x, but expected %x", joined, ciphertext)
+		}
+	}
+
+	if _, _, err := aead.SplitTag(make([]byte, 30)); err != errCiphertextSize {
+		t.Errorf("Error without the magic was %v, but expected %v", err, errCiphertextSize)
+	}
+}
+
+func TestShortTag(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	ciphertext, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	short, err := aead.ShortTag(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if short != 0x85632d07 {
+		t.Errorf("ShortTag was %08x, but expected %08x", short, 0x85632d07)
+	}
+
+	if again, _ := aead.ShortTag(append([]byte(nil), ciphertext...)); again != short {
+		t.Errorf("ShortTag was %08x, but previously %08x", again, short)
+	}
+
+	tampered := append([]byte(nil), ciphertext...)
+	tampered[2] ^= 1
+
+	if v, _ := aead.ShortTag(tampered); v == short {
+		t.Errorf("ShortTag of a tampered ciphertext was unchanged at %08x", v)
+	}
+
+	if _, err := aead.ShortTag(ciphertext[:15]); err != errCiphertextSize {
+		t.Errorf("Error for a short ciphertext was %v, but expected %v", err, errCiphertextSize)
+	}
+}
+
+func TestSealDetached(t *testing.T) {
+	// https://tools.ietf.org/html/rfc5297#appendix-A.1
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	ciphertext, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag := make([]byte, aead.TagSize())
+	body := aead.SealDetached(nil, tag, nil, plaintext, data)
+
+	if expected := ciphertext[:16]; !bytes.Equal(tag, expected) {
+		t.Errorf("Tag was %x, but expected %x", tag, expected)
+	}
+
+	if expected := ciphertext[16:]; !bytes.Equal(body, expected) {
+		t.Errorf("Body was %x, but expected %x", body, expected)
+	}
+
+	actual, err := aead.OpenDetached(nil, nil, body, tag, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+
+	// in place
+	buf := append([]byte(nil), body...)
+	if actual, err := aead.OpenDetached(buf[:0], nil, buf, tag, data); err != nil || !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext opened in place was %x (%v), but expected %x", actual, err, plaintext)
+	}
+
+	for i := range tag {
+		forged := append([]byte(nil), tag...)
+		forged[i] ^= 1
+
+		if _, err := aead.OpenDetached(nil, nil, body, forged, data); err != ErrAuthentication {
+			t.Errorf("Error for a tag forged at byte %d was %v, but expected %v", i, err, ErrAuthentication)
+		}
+	}
+
+	for _, n := range []int{0, 15, 17} {
+		if _, err := aead.OpenDetached(nil, nil, body, make([]byte, n), data); err != ErrAuthentication {
+			t.Errorf("Error for a %d-byte tag was %v, but expected %v", n, err, ErrAuthentication)
+		}
+	}
+}
+
+func TestSealDetachedMagic(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	nonce, _ := hex.DecodeString("09f911029d74e35bd84156c5635688c0")
+	plaintext := []byte("hello, world")
+
+	aead, err := New(key, aes.NewCipher, WithMagic([]byte("SIV1")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag := make([]byte, aead.TagSize())
+	body := aead.SealDetached(nil, tag, nonce, plaintext, []byte("data"))
+
+	if joined := aead.JoinTag(tag, body); !bytes.Equal(joined, aead.Seal(nil, nonce, plaintext, []byte("data"))) {
+		t.Errorf("Joined ciphertext %x differed from Seal", joined)
+	}
+
+	if _, err := aead.OpenDetached(nil, nil, body, tag, []byte("data")); err != ErrAuthentication {
+		t.Errorf("Error without the nonce was %v, but expected %v", err, ErrAuthentication)
+	}
+}
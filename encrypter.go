@@ -0,0 +1,138 @@
+package siv
+
+import (
+	"github.com/stripe/siv-go/internal/cmac"
+)
+
+// adWriter computes S2V over a single associated data component written a
+// piece at a time, followed by the plaintext.
+type adWriter struct {
+	s    *SIV
+	st   s2vState
+	done bool
+}
+
+func (s *SIV) newADWriter() (adWriter, error) {
+	if err := s.checkNonce(nil); err != nil {
+		return adWriter{}, err
+	}
+
+	st := newS2V(s.newMAC())
+	if s.magic != nil {
+		st.add(s.magic)
+	}
+
+	// the component's doubling has no effect on the running MAC, so it can be
+	// done before anything is written
+	cmac.Dbl(st.d)
+
+	return adWriter{s: s, st: st}, nil
+}
+
+func (w *adWriter) write(p []byte) {
+	if w.done {
+		panic("siv: WriteAD called after the message was sealed or opened")
+	}
+
+	_, _ = w.st.h.Write(p)
+}
+
+// sum returns the synthetic IV for the associated data written and plaintext.
+func (w *adWriter) sum(plaintext []byte) []byte {
+	if w.done {
+		panic("siv: Encrypter or Decrypter reused")
+	}
+	w.done = true
+
+	w.st.fold()
+	return w.st.sum(plaintext)
+}
+
+// An Encrypter seals a single message whose associated data is written to it
+// incrementally, as it arrives, rather than assembled into one buffer first.
+// The result is the same as that of Seal with no nonce and the concatenation
+// of everything written as the data, which is authenticated as a single
+// component even if nothing is written.
+type Encrypter struct {
+	w adWriter
+}
+
+// Encrypter returns a new Encrypter. It returns an error if the AEAD requires
+// a nonce.
+func (s *SIV) Encrypter() (*Encrypter, error) {
+	w, err := s.newADWriter()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Encrypter{w: w}, nil
+}
+
+// WriteAD appends p to the associated data. p is not retained.
+func (e *Encrypter) WriteAD(p []byte) {
+	e.w.write(p)
+}
+
+// Seal appends the ciphertext of plaintext to dst as Seal does. The Encrypter
+// cannot be used again afterwards.
+func (e *Encrypter) Seal(dst, plaintext []byte) []byte {
+	s := e.w.s
+	if s.closed.Load() {
+		panic("siv: Seal called after Close")
+	}
+
+	checkOverlap(dst, s.Overhead()+len(plaintext), plaintext, nil, nil)
+
+	sc := s.getScratch()
+	defer s.putScratch(sc)
+
+	return s.sealBody(sc, dst, plaintext, e.w.sum(plaintext))
+}
+
+// A Decrypter opens a single message sealed by an Encrypter, or by Seal with
+// no nonce, whose associated data is written to it incrementally.
+type Decrypter struct {
+	w adWriter
+}
+
+// Decrypter returns a new Decrypter. It returns an error if the AEAD requires
+// a nonce.
+func (s *SIV) Decrypter() (*Decrypter, error) {
+	w, err := s.newADWriter()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Decrypter{w: w}, nil
+}
+
+// WriteAD appends p to the associated data. p is not retained.
+func (d *Decrypter) WriteAD(p []byte) {
+	d.w.write(p)
+}
+
+// Open appends the plaintext of ciphertext to dst as Open does. The
+// Decrypter cannot be used again afterwards.
+func (d *Decrypter) Open(dst, ciphertext []byte) ([]byte, error) {
+	s := d.w.s
+	if s.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	if !s.Valid(ciphertext) {
+		return nil, ErrAuthentication
+	}
+
+	if s.tooLong(ciphertext) {
+		return nil, errMaxOpenLen
+	}
+
+	checkOverlap(dst, len(ciphertext)-s.Overhead(), ciphertext, nil, nil)
+
+	sc := s.getScratch()
+	defer s.putScratch(sc)
+
+	ciphertext = ciphertext[len(s.magic):]
+	ret, plaintext := s.decryptBody(sc, dst, ciphertext[:s.tagSize()], ciphertext[s.tagSize():])
+	return authenticate(ret, plaintext, sc.v, d.w.sum(plaintext))
+}
@@ -0,0 +1,164 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+)
+
+func TestEncrypter(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	expected, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, split := range [][]int{{24}, {0, 24}, {1, 23}, {16, 8}, {3, 5, 7, 9}} {
+		enc, err := aead.Encrypter()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dec, err := aead.Decrypter()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p := data
+		for _, n := range split {
+			enc.WriteAD(p[:n])
+			dec.WriteAD(p[:n])
+			p = p[n:]
+		}
+
+		ciphertext := enc.Seal(nil, plaintext)
+		if !bytes.Equal(ciphertext, expected) {
+			t.Errorf("Ciphertext for writes of %v was %x, but expected %x", split, ciphertext, expected)
+		}
+
+		actual, err := dec.Open(nil, ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual, plaintext) {
+			t.Errorf("Plaintext for writes of %v was %x, but expected %x", split, actual, plaintext)
+		}
+	}
+
+	dec, err := aead.Decrypter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dec.WriteAD(data[1:])
+	if _, err := dec.Open(nil, expected); err != ErrAuthentication {
+		t.Errorf("Error with other data was %v, but expected %v", err, ErrAuthentication)
+	}
+}
+
+func TestEncrypterVariants(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	plaintext := bytes.Repeat([]byte("plaintext"), 10)
+	data := bytes.Repeat([]byte("header"), 20)
+
+	plain, _ := New(key, aes.NewCipher)
+	pmacSIV, _ := NewPMACSIV(key, aes.NewCipher)
+	magic, _ := New(key, aes.NewCipher, WithMagic([]byte("v1")))
+
+	for _, aead := range []*SIV{plain, pmacSIV, magic} {
+		enc, err := aead.Encrypter()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, b := range bytes.SplitAfter(data, []byte("r")) {
+			enc.WriteAD(b)
+		}
+
+		if actual, expected := enc.Seal(nil, plaintext), aead.Seal(nil, nil, plaintext, data); !bytes.Equal(actual, expected) {
+			t.Errorf("Ciphertext was %x, but expected %x", actual, expected)
+		}
+
+		// nothing written is an empty component
+		enc, err = aead.Encrypter()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual, expected := enc.Seal(nil, plaintext), aead.Seal(nil, nil, plaintext, []byte{}); !bytes.Equal(actual, expected) {
+			t.Errorf("Ciphertext with no data was %x, but expected %x", actual, expected)
+		}
+	}
+}
+
+func TestEncrypterReuse(t *testing.T) {
+	aead, _ := New(make([]byte, 32), aes.NewCipher)
+
+	enc, err := aead.Encrypter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enc.Seal(nil, nil)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected WriteAD after Seal to panic")
+		}
+	}()
+	enc.WriteAD([]byte("data"))
+}
+
+func TestEncrypterNonceSize(t *testing.T) {
+	aead, _ := New(make([]byte, 32), aes.NewCipher, WithNonceSize(12))
+
+	if _, err := aead.Encrypter(); err != errNonceSize {
+		t.Errorf("Encrypter error was %v, but expected %v", err, errNonceSize)
+	}
+
+	if _, err := aead.Decrypter(); err != errNonceSize {
+		t.Errorf("Decrypter error was %v, but expected %v", err, errNonceSize)
+	}
+}
+
+func TestEncrypterAfterClose(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	plaintext := []byte("plaintext")
+	data := []byte("header")
+
+	for _, alg := range []func([]byte, func([]byte) (cipher.Block, error), ...Option) (*SIV, error){New, NewPMACSIV} {
+		aead, _ := alg(key, aes.NewCipher)
+		expected := aead.Seal(nil, nil, plaintext, data)[:aead.TagSize()]
+
+		enc, err := aead.Encrypter()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dec, err := aead.Decrypter()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		enc.WriteAD(data[:3])
+		_ = aead.Close()
+		enc.WriteAD(data[3:])
+
+		// the Encrypter's subkeys are its own, so wiping the AEAD's does not
+		// silently change its tag
+		if v := enc.w.sum(plaintext); !bytes.Equal(v, expected) {
+			t.Errorf("Synthetic IV after Close was %x, but expected %x", v, expected)
+		}
+
+		if _, err := dec.Open(nil, expected); err != ErrClosed {
+			t.Errorf("Error from Decrypter after Close was %v, but expected %v", err, ErrClosed)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package siv
+
+// SealWithFooter seals plaintext, authenticating both a header and a footer
+// which are not encrypted. The header and footer are distinct associated data
+// components, with a nil header or footer treated as empty. S2V is always
+// given the header, then the footer, then the plaintext, so the two cannot be
+// exchanged, even if they are the same length, nor the boundary between them
+// moved, without the ciphertext failing to open. The result is the synthetic
+// IV followed by the ciphertext; the footer is not included and must be
+// supplied again to OpenWithFooter.
+func (s *SIV) SealWithFooter(plaintext, header, footer []byte) []byte {
+	return s.mustSeal(nil, plaintext, nil, nonNil(header), nonNil(footer))
+}
+
+// OpenWithFooter opens a ciphertext sealed by SealWithFooter. The header and
+// footer must be given in the same order as they were sealed.
+func (s *SIV) OpenWithFooter(ciphertext, header, footer []byte) ([]byte, error) {
+	return s.open(nil, ciphertext, nil, nonNil(header), nonNil(footer))
+}
+
+// nonNil returns b, or an empty slice if b is nil, so that it is included as
+// an associated data component rather than skipped.
+func nonNil(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	return b
+}
@@ -0,0 +1,98 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSealWithFooter(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	header, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	footer, _ := hex.DecodeString("0000000e")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.SealWithFooter(plaintext, header, footer)
+
+	if v, want := len(ciphertext), len(plaintext)+aead.Overhead(); v != want {
+		t.Errorf("Ciphertext length was %d, but expected %d", v, want)
+	}
+
+	actual, err := aead.OpenWithFooter(ciphertext, header, footer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+}
+
+func TestSealWithFooterBadFooter(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	header, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	footer, _ := hex.DecodeString("0000000e")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.SealWithFooter(plaintext, header, footer)
+
+	footer[3] ^= 1
+
+	actual, err := aead.OpenWithFooter(ciphertext, header, footer)
+	if err == nil {
+		t.Fatalf("Plaintext returned instead of error: %x", actual)
+	}
+
+	// the footer must not be movable into the header
+	actual, err = aead.OpenWithFooter(ciphertext, append(header, footer...), nil)
+	if err == nil {
+		t.Fatalf("Plaintext returned instead of error: %x", actual)
+	}
+}
+
+func TestSealWithFooterSwapped(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	header := []byte("header:v1")
+	footer := []byte("footer:v1")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.SealWithFooter(plaintext, header, footer)
+
+	actual, err := aead.OpenWithFooter(ciphertext, header, footer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+
+	if swapped := aead.SealWithFooter(plaintext, footer, header); bytes.Equal(swapped[:16], ciphertext[:16]) {
+		t.Errorf("Swapping the header and footer did not change the tag %x", swapped[:16])
+	}
+
+	if actual, err := aead.OpenWithFooter(ciphertext, footer, header); err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v (plaintext %x)", err, ErrAuthentication, actual)
+	}
+
+	// nor can the boundary between them move
+	if actual, err := aead.OpenWithFooter(ciphertext, []byte("header:v1f"), []byte("ooter:v1")); err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v (plaintext %x)", err, ErrAuthentication, actual)
+	}
+}
@@ -0,0 +1,162 @@
+package siv
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	errGCMSIVKeySize = errors.New("invalid AES-GCM-SIV key size: must be 16 or 32 bytes")
+)
+
+const (
+	gcmSIVNonceSize = 12
+	gcmSIVTagSize   = 16
+
+	// gcmSIVMaxSize is the largest plaintext or associated data RFC 8452
+	// allows, 2^36 bytes.
+	gcmSIVMaxSize = 1 << 36
+)
+
+type gcmSIV struct {
+	b       cipher.Block // the key-generating key
+	keySize int
+}
+
+// NewGCMSIV returns AES-GCM-SIV, as described in RFC 8452, with the given
+// 16- or 32-byte key. Unlike the SIV-CMAC mode returned by New, it takes a
+// single 12-byte nonce, appends its 16-byte tag to the ciphertext, and derives
+// fresh record keys for every nonce.
+func NewGCMSIV(key []byte) (cipher.AEAD, error) {
+	if len(key) != 16 && len(key) != 32 {
+		return nil, errGCMSIVKeySize
+	}
+
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gcmSIV{b: b, keySize: len(key)}, nil
+}
+
+func (g *gcmSIV) NonceSize() int {
+	return gcmSIVNonceSize
+}
+
+func (g *gcmSIV) Overhead() int {
+	return gcmSIVTagSize
+}
+
+func (g *gcmSIV) Seal(dst, nonce, plaintext, data []byte) []byte {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("siv: invalid nonce size")
+	}
+	if uint64(len(plaintext)) > gcmSIVMaxSize || uint64(len(data)) > gcmSIVMaxSize {
+		panic("siv: message too large for GCM-SIV")
+	}
+	checkOverlap(dst, len(plaintext)+gcmSIVTagSize, plaintext, [][]byte{data}, nil)
+
+	auth, enc := g.deriveKeys(nonce)
+
+	var tag [gcmSIVTagSize]byte
+	g.tag(tag[:], auth, enc, nonce, plaintext, data)
+
+	ret, out := sliceForAppend(dst, len(plaintext)+gcmSIVTagSize)
+	gcmSIVCTR(enc, out[:len(plaintext)], plaintext, tag[:])
+	copy(out[len(plaintext):], tag[:])
+
+	return ret
+}
+
+func (g *gcmSIV) Open(dst, nonce, ciphertext, data []byte) ([]byte, error) {
+	if len(nonce) != gcmSIVNonceSize {
+		return nil, errNonceSize
+	}
+	if len(ciphertext) < gcmSIVTagSize ||
+		uint64(len(ciphertext)) > gcmSIVMaxSize+gcmSIVTagSize ||
+		uint64(len(data)) > gcmSIVMaxSize {
+		return nil, ErrAuthentication
+	}
+
+	n := len(ciphertext) - gcmSIVTagSize
+	tag := ciphertext[n:]
+	checkOverlap(dst, n, ciphertext[:n], [][]byte{data, tag}, nil)
+
+	auth, enc := g.deriveKeys(nonce)
+
+	ret, out := sliceForAppend(dst, n)
+	gcmSIVCTR(enc, out, ciphertext[:n], tag)
+
+	var expected [gcmSIVTagSize]byte
+	g.tag(expected[:], auth, enc, nonce, out, data)
+
+	if subtle.ConstantTimeCompare(expected[:], tag) != 1 {
+		for i := range out {
+			out[i] = 0
+		}
+		return nil, ErrAuthentication
+	}
+
+	return ret, nil
+}
+
+// deriveKeys returns the message-authentication key and the message-encryption
+// block cipher for the given nonce.
+func (g *gcmSIV) deriveKeys(nonce []byte) ([]byte, cipher.Block) {
+	var in, out [16]byte
+	copy(in[4:], nonce)
+
+	keys := make([]byte, 16+g.keySize)
+	for i := 0; i < len(keys)/8; i++ {
+		binary.LittleEndian.PutUint32(in[:4], uint32(i))
+		g.b.Encrypt(out[:], in[:])
+		copy(keys[8*i:], out[:8])
+	}
+
+	enc, err := aes.NewCipher(keys[16:])
+	if err != nil {
+		panic(err) // the key size was checked by NewGCMSIV
+	}
+
+	return keys[:16], enc
+}
+
+// tag computes the tag over the plaintext and associated data into dst.
+func (g *gcmSIV) tag(dst, auth []byte, enc cipher.Block, nonce, plaintext, data []byte) {
+	var lengths [16]byte
+	binary.LittleEndian.PutUint64(lengths[:8], uint64(len(data))*8)
+	binary.LittleEndian.PutUint64(lengths[8:], uint64(len(plaintext))*8)
+
+	p := newPolyval(auth)
+	p.update(data)
+	p.update(plaintext)
+	p.block(lengths[:])
+
+	s := p.sum(dst[:0])
+	for i, v := range nonce {
+		s[i] ^= v
+	}
+	s[15] &= 0x7f
+
+	enc.Encrypt(dst, s)
+}
+
+// gcmSIVCTR encrypts or decrypts src into dst using AES-CTR with a 32-bit
+// little-endian counter, starting from the tag with its top bit set.
+func gcmSIVCTR(enc cipher.Block, dst, src, tag []byte) {
+	var ctr, ks [16]byte
+	copy(ctr[:], tag)
+	ctr[15] |= 0x80
+
+	for len(src) > 0 {
+		enc.Encrypt(ks[:], ctr[:])
+		binary.LittleEndian.PutUint32(ctr[:4], binary.LittleEndian.Uint32(ctr[:4])+1)
+
+		n := subtle.XORBytes(dst, src, ks[:])
+		dst, src = dst[n:], src[n:]
+	}
+}
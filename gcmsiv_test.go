@@ -0,0 +1,126 @@
+package siv
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// https://tools.ietf.org/html/rfc8452#appendix-C
+var gcmSIVVectors = []struct {
+	key, nonce, plaintext, data, result string
+}{
+	{
+		key:    "01000000000000000000000000000000",
+		nonce:  "030000000000000000000000",
+		result: "dc20e2d83f25705bb49e439eca56de25",
+	},
+	{
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		plaintext: "0100000000000000",
+		result:    "b5d839330ac7b786578782fff6013b815b287c22493a364c",
+	},
+	{
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		plaintext: "010000000000000000000000",
+		result:    "7323ea61d05932260047d942a4978db357391a0bc4fdec8b0d106639",
+	},
+	{
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		plaintext: "01000000000000000000000000000000",
+		result:    "743f7c8077ab25f8624e2e948579cf77303aaf90f6fe21199c6068577437a0c4",
+	},
+	{
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		plaintext: "0100000000000000000000000000000002000000000000000000000000000000",
+		result:    "84e07e62ba83a6585417245d7ec413a9fe427d6315c09b57ce45f2e3936a94451a8e45dcd4578c667cd86847bf6155ff",
+	},
+	{
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		plaintext: "0200000000000000",
+		data:      "01",
+		result:    "1e6daba35669f4273b0a1a2560969cdf790d99759abd1508",
+	},
+	{
+		key:    "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:  "030000000000000000000000",
+		result: "07f5f4169bbf55a8400cd47ea6fd400f",
+	},
+	{
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		plaintext: "0100000000000000",
+		result:    "c2ef328e5c71c83b843122130f7364b761e0b97427e3df28",
+	},
+}
+
+func TestGCMSIV(t *testing.T) {
+	for i, v := range gcmSIVVectors {
+		key, _ := hex.DecodeString(v.key)
+		nonce, _ := hex.DecodeString(v.nonce)
+		plaintext, _ := hex.DecodeString(v.plaintext)
+		data, _ := hex.DecodeString(v.data)
+		expected, _ := hex.DecodeString(v.result)
+
+		aead, err := NewGCMSIV(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := aead.Seal(nil, nonce, plaintext, data)
+		if !bytes.Equal(actual, expected) {
+			t.Errorf("Ciphertext %d was %x, but expected %x", i, actual, expected)
+		}
+
+		decrypted, err := aead.Open(nil, nonce, actual, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("Plaintext %d was %x, but expected %x", i, decrypted, plaintext)
+		}
+	}
+}
+
+func TestGCMSIVTampered(t *testing.T) {
+	key, _ := hex.DecodeString("01000000000000000000000000000000")
+	nonce, _ := hex.DecodeString("030000000000000000000000")
+
+	aead, err := NewGCMSIV(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nonce, []byte("hello, world"), []byte("data"))
+
+	for i := range ciphertext {
+		tampered := append([]byte(nil), ciphertext...)
+		tampered[i] ^= 1
+
+		if _, err := aead.Open(nil, nonce, tampered, []byte("data")); err != ErrAuthentication {
+			t.Errorf("Open of ciphertext tampered at byte %d returned %v", i, err)
+		}
+	}
+
+	if _, err := aead.Open(nil, nonce, ciphertext, []byte("other")); err != ErrAuthentication {
+		t.Errorf("Open with the wrong data returned %v", err)
+	}
+
+	if _, err := aead.Open(nil, nonce, ciphertext[:15], []byte("data")); err != ErrAuthentication {
+		t.Errorf("Open of a short ciphertext returned %v", err)
+	}
+}
+
+func TestGCMSIVKeySize(t *testing.T) {
+	for _, n := range []int{0, 24, 64} {
+		if _, err := NewGCMSIV(make([]byte, n)); err != errGCMSIVKeySize {
+			t.Errorf("NewGCMSIV with a %d-byte key returned %v, but expected %v", n, err, errGCMSIVKeySize)
+		}
+	}
+}
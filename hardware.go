@@ -0,0 +1,21 @@
+package siv
+
+import (
+	"sync"
+)
+
+// HardwareAccelerated reports whether the CPU supports the AES instructions
+// crypto/aes uses in place of its software implementation: AES-NI on amd64,
+// the ARMv8 cryptography extensions on arm64, CPACF on s390x, and the vector
+// AES instructions of POWER8 and later on ppc64 and ppc64le. It is
+// informational only, and says nothing about other block ciphers passed to New.
+//
+// Support is detected without third-party packages: with CPUID on amd64, from
+// the auxiliary vector on Linux arm64, and by architecture elsewhere. It is
+// done on the first call rather than when the package is initialized, so
+// programs which never ask pay nothing for it.
+func HardwareAccelerated() bool {
+	return hardwareAccelerated()
+}
+
+var hardwareAccelerated = sync.OnceValue(hasAES)
@@ -0,0 +1,14 @@
+package siv
+
+// cpuid executes the CPUID instruction with the given EAX and ECX inputs.
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+func hasAES() bool {
+	if max, _, _, _ := cpuid(0, 0); max < 1 {
+		return false
+	}
+
+	// AES-NI is bit 25 of ECX for leaf 1
+	_, _, ecx, _ := cpuid(1, 0)
+	return ecx&(1<<25) != 0
+}
@@ -0,0 +1,42 @@
+package siv
+
+import (
+	"encoding/binary"
+	"os"
+	"runtime"
+)
+
+const (
+	atHWCap  = 16     // AT_HWCAP in the auxiliary vector
+	hwCapAES = 1 << 3 // HWCAP_AES
+)
+
+func hasAES() bool {
+	switch runtime.GOOS {
+	case "darwin", "ios":
+		// every Apple arm64 CPU has the cryptography extensions
+		return true
+	case "linux", "android":
+		return auxvHasAES()
+	}
+	return false
+}
+
+// auxvHasAES reads the hardware capabilities the kernel reports in the
+// auxiliary vector, a list of 8-byte tag and value pairs.
+func auxvHasAES() bool {
+	auxv, err := os.ReadFile("/proc/self/auxv")
+	if err != nil {
+		return false
+	}
+
+	for len(auxv) >= 16 {
+		tag := binary.LittleEndian.Uint64(auxv)
+		val := binary.LittleEndian.Uint64(auxv[8:])
+		if tag == atHWCap {
+			return val&hwCapAES != 0
+		}
+		auxv = auxv[16:]
+	}
+	return false
+}
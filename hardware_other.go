@@ -0,0 +1,17 @@
+//go:build !amd64 && !arm64
+
+package siv
+
+import (
+	"runtime"
+)
+
+func hasAES() bool {
+	switch runtime.GOARCH {
+	case "s390x", "ppc64", "ppc64le":
+		// every CPU Go supports for these has the instructions: z13 and
+		// later, and POWER8 and later
+		return true
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package siv
+
+import (
+	"testing"
+)
+
+func TestHardwareAccelerated(t *testing.T) {
+	expected := HardwareAccelerated()
+
+	for i := 0; i < 10; i++ {
+		if v := HardwareAccelerated(); v != expected {
+			t.Fatalf("HardwareAccelerated was %v, but previously %v", v, expected)
+		}
+	}
+
+	t.Logf("HardwareAccelerated: %v", expected)
+}
@@ -0,0 +1,158 @@
+// Package cmac implements the CMAC (OMAC1) message authentication code
+// described in RFC 4493, and the doubling in GF(2^n) it is built on, so that
+// package siv needs nothing outside the standard library. Its subkeys are
+// derived once per key by Subkeys and shared across instances.
+package cmac
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+)
+
+// A CMAC computes CMAC with a 64 or 128-bit block cipher. It implements
+// hash.Hash.
+type CMAC struct {
+	b         cipher.Block
+	k1, k2    []byte
+	x, buf, t []byte
+	n         int
+}
+
+// Subkeys derives the CMAC subkeys K1 and K2 for the given block cipher.
+func Subkeys(b cipher.Block) (k1, k2 []byte) {
+	k1 = make([]byte, b.BlockSize())
+	b.Encrypt(k1, k1)
+	Dbl(k1)
+
+	k2 = make([]byte, len(k1))
+	copy(k2, k1)
+	Dbl(k2)
+
+	return k1, k2
+}
+
+// New returns a CMAC using b and its subkeys, as returned by Subkeys.
+func New(b cipher.Block, k1, k2 []byte) *CMAC {
+	h := new(CMAC)
+	h.SetScratch(make([]byte, 3*b.BlockSize()))
+	h.SetKey(b, k1, k2)
+	return h
+}
+
+// SetScratch makes h keep its state in buf, which must be three blocks long,
+// so that callers can supply memory they pool themselves.
+func (h *CMAC) SetScratch(buf []byte) {
+	size := len(buf) / 3
+	h.x, h.buf, h.t = buf[:size], buf[size:2*size], buf[2*size:]
+}
+
+// SetKey keys h with b and its subkeys, and resets it.
+func (h *CMAC) SetKey(b cipher.Block, k1, k2 []byte) {
+	h.b, h.k1, h.k2 = b, k1, k2
+	h.Reset()
+}
+
+// Wipe zeroes h's state and drops its references to the key.
+func (h *CMAC) Wipe() {
+	for _, v := range [][]byte{h.x, h.buf, h.t} {
+		for i := range v {
+			v[i] = 0
+		}
+	}
+
+	h.b, h.k1, h.k2, h.n = nil, nil, nil, 0
+}
+
+func (h *CMAC) Size() int {
+	return h.b.BlockSize()
+}
+
+func (h *CMAC) BlockSize() int {
+	return h.b.BlockSize()
+}
+
+func (h *CMAC) Reset() {
+	for i := range h.x {
+		h.x[i] = 0
+	}
+	h.n = 0
+}
+
+func (h *CMAC) Write(p []byte) (int, error) {
+	n := len(p)
+	size := len(h.buf)
+
+	if h.n < size {
+		c := copy(h.buf[h.n:], p)
+		h.n += c
+		p = p[c:]
+	}
+
+	if len(p) == 0 {
+		return n, nil
+	}
+
+	// The final block is treated differently, so a full buffer is only
+	// processed once more input arrives, and the last block of p is kept.
+	subtle.XORBytes(h.x, h.x, h.buf)
+	h.b.Encrypt(h.x, h.x)
+
+	for len(p) > size {
+		subtle.XORBytes(h.x, h.x, p[:size])
+		h.b.Encrypt(h.x, h.x)
+		p = p[size:]
+	}
+
+	h.n = copy(h.buf, p)
+	return n, nil
+}
+
+func (h *CMAC) Sum(b []byte) []byte {
+	x := h.t
+	copy(x, h.buf[:h.n])
+	for i := h.n; i < len(x); i++ {
+		x[i] = 0
+	}
+
+	if h.n == len(h.buf) {
+		subtle.XORBytes(x, x, h.k1)
+	} else {
+		x[h.n] = 0x80
+		subtle.XORBytes(x, x, h.k2)
+	}
+	subtle.XORBytes(x, x, h.x)
+
+	h.b.Encrypt(x, x)
+	return append(b, x...)
+}
+
+// Dbl multiplies b by x in GF(2^n), where n is 64 or 128 bits. It runs in
+// constant time: the reduction is applied through a mask of the carried-out
+// bit rather than a branch on it.
+func Dbl(b []byte) {
+	mask := -(b[0] >> 7)
+	shiftLeft(b)
+	b[len(b)-1] ^= Rb(len(b)) & mask
+}
+
+// Rb returns the low byte of the reduction polynomial for blocks of size
+// bytes, the constant R_b of RFC 4493.
+func Rb(size int) byte {
+	switch size {
+	case 8:
+		return 0x1b
+	case 16:
+		return 0x87
+	}
+	panic("cmac: unsupported block size")
+}
+
+func shiftLeft(b []byte) {
+	overflow := byte(0)
+	for i := len(b) - 1; i >= 0; i-- {
+		v := b[i]
+		b[i] <<= 1
+		b[i] |= overflow
+		overflow = (v & 0x80) >> 7
+	}
+}
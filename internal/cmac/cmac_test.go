@@ -0,0 +1,137 @@
+package cmac
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/des"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSubkeys(t *testing.T) {
+	// https://tools.ietf.org/html/rfc4493#section-4
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	expectedK1, _ := hex.DecodeString("fbeed618357133667c85e08f7236a8de")
+	expectedK2, _ := hex.DecodeString("f7ddac306ae266ccf90bc11ee46d513b")
+
+	block, _ := aes.NewCipher(key)
+	k1, k2 := Subkeys(block)
+
+	if !bytes.Equal(k1, expectedK1) {
+		t.Errorf("K1 was %x, but expected %x", k1, expectedK1)
+	}
+
+	if !bytes.Equal(k2, expectedK2) {
+		t.Errorf("K2 was %x, but expected %x", k2, expectedK2)
+	}
+}
+
+func TestCMAC(t *testing.T) {
+	// https://tools.ietf.org/html/rfc4493#section-4
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	msg, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710")
+
+	block, _ := aes.NewCipher(key)
+	k1, k2 := Subkeys(block)
+
+	for _, v := range []struct {
+		len int
+		mac string
+	}{
+		{0, "bb1d6929e95937287fa37d129b756746"},
+		{16, "070a16b46b4d4144f79bdd9dd04a287c"},
+		{40, "dfa66747de9ae63030ca32611497c827"},
+		{64, "51f0bebf7e3b9d92fc49741779363cfe"},
+	} {
+		expected, _ := hex.DecodeString(v.mac)
+
+		h := New(block, k1, k2)
+		_, _ = h.Write(msg[:v.len])
+		actual := h.Sum(nil)
+
+		if !bytes.Equal(actual, expected) {
+			t.Errorf("CMAC of %d bytes was %x, but expected %x", v.len, actual, expected)
+		}
+	}
+}
+
+func TestCMAC64(t *testing.T) {
+	// computed with OpenSSL's CMAC over DES-EDE3-CBC
+	key, _ := hex.DecodeString("8aa83bf8cbda10620bc1bf19fbb6cd5808f2f8e02b9f1a2c")
+	msg, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a57")
+
+	block, _ := des.NewTripleDESCipher(key)
+	k1, k2 := Subkeys(block)
+
+	for _, v := range []struct {
+		len int
+		mac string
+	}{
+		{0, "8e5ce9f2413f1bc7"},
+		{8, "b385b41161319d5e"},
+		{20, "f5d49b680aeba7b4"},
+	} {
+		expected, _ := hex.DecodeString(v.mac)
+
+		h := New(block, k1, k2)
+		_, _ = h.Write(msg[:v.len])
+		actual := h.Sum(nil)
+
+		if !bytes.Equal(actual, expected) {
+			t.Errorf("CMAC of %d bytes was %x, but expected %x", v.len, actual, expected)
+		}
+	}
+}
+
+func TestCMACWriteSplits(t *testing.T) {
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	block, _ := aes.NewCipher(key)
+	k1, k2 := Subkeys(block)
+
+	msg := make([]byte, 70)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+
+	for n := 0; n <= len(msg); n++ {
+		h := New(block, k1, k2)
+		_, _ = h.Write(msg[:n])
+		expected := h.Sum(nil)
+
+		for i := 0; i <= n; i++ {
+			for j := i; j <= n; j++ {
+				h.Reset()
+				_, _ = h.Write(msg[:i])
+				_, _ = h.Write(msg[i:j])
+				_, _ = h.Write(msg[j:n])
+
+				if actual := h.Sum(nil); !bytes.Equal(actual, expected) {
+					t.Fatalf("CMAC of %d bytes written at %d and %d was %x, but expected %x", n, i, j, actual, expected)
+				}
+			}
+		}
+	}
+}
+
+func TestDbl(t *testing.T) {
+	for _, v := range []struct {
+		in, out string
+	}{
+		{"0000000000000001", "0000000000000002"},
+		{"4000000000000000", "8000000000000000"},
+		{"8000000000000000", "000000000000001b"},
+		{"c000000000000001", "8000000000000019"},
+		{"00000000000000000000000000000001", "00000000000000000000000000000002"},
+		{"80000000000000000000000000000000", "00000000000000000000000000000087"},
+		{"c0000000000000000000000000000001", "80000000000000000000000000000085"},
+	} {
+		b, _ := hex.DecodeString(v.in)
+		expected, _ := hex.DecodeString(v.out)
+
+		Dbl(b)
+
+		if !bytes.Equal(b, expected) {
+			t.Errorf("dbl(%s) was %x, but expected %x", v.in, b, expected)
+		}
+	}
+}
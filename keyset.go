@@ -0,0 +1,150 @@
+package siv
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+)
+
+const (
+	keysetVersion    = 1
+	keysetPrefixSize = 5
+)
+
+var (
+	errKeyID     = errors.New("key ID already in keyset")
+	errNoKey     = errors.New("key ID not in keyset")
+	errNoPrimary = errors.New("keyset has no primary key")
+	errPrimary   = errors.New("cannot remove the primary key")
+)
+
+// A Keyset holds several AEADs under 32-bit key IDs, for key rotation: it
+// seals with its primary key, and opens with whichever key a ciphertext names.
+// Each ciphertext is a version byte of 1 and the key ID, as a 4-byte
+// big-endian integer, followed by a ciphertext sealed with that 5-byte prefix
+// and the associated data as separate components.
+//
+// A Keyset is safe for concurrent use, including changes to its keys while
+// other goroutines seal and open.
+type Keyset struct {
+	mu         sync.RWMutex
+	keys       map[uint32]*SIV
+	primary    uint32
+	hasPrimary bool
+}
+
+// NewKeyset returns an empty Keyset. Seal fails until a primary key is set.
+func NewKeyset() *Keyset {
+	return &Keyset{keys: make(map[uint32]*SIV)}
+}
+
+// Add adds an AEAD under the given key ID, which must not already be in use.
+func (k *Keyset) Add(id uint32, s *SIV) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; ok {
+		return errKeyID
+	}
+
+	k.keys[id] = s
+	return nil
+}
+
+// SetPrimary makes the key with the given ID the one used by Seal. Ciphertexts
+// sealed with earlier primary keys can still be opened while those keys remain
+// in the keyset.
+func (k *Keyset) SetPrimary(id uint32) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return errNoKey
+	}
+
+	k.primary, k.hasPrimary = id, true
+	return nil
+}
+
+// Remove removes the key with the given ID, after which ciphertexts sealed
+// with it can no longer be opened. The primary key cannot be removed.
+func (k *Keyset) Remove(id uint32) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return errNoKey
+	}
+
+	if k.hasPrimary && id == k.primary {
+		return errPrimary
+	}
+
+	delete(k.keys, id)
+	return nil
+}
+
+// Primary returns the ID of the primary key, and whether one is set.
+func (k *Keyset) Primary() (uint32, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.primary, k.hasPrimary
+}
+
+// Seal seals plaintext with the primary key.
+func (k *Keyset) Seal(plaintext, data []byte) ([]byte, error) {
+	id, s, err := k.primaryKey()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, keysetPrefixSize, keysetPrefixSize+s.Overhead()+len(plaintext))
+	out[0] = keysetVersion
+	binary.BigEndian.PutUint32(out[1:], id)
+
+	return s.seal(out, plaintext, nil, out[:keysetPrefixSize], data)
+}
+
+// Open opens a ciphertext sealed by Seal with any key still in the keyset. A
+// ciphertext naming an unknown key or version fails with ErrAuthentication,
+// as a forged one does.
+func (k *Keyset) Open(ciphertext, data []byte) ([]byte, error) {
+	if len(ciphertext) < keysetPrefixSize || ciphertext[0] != keysetVersion {
+		return nil, ErrAuthentication
+	}
+
+	s, ok := k.key(binary.BigEndian.Uint32(ciphertext[1:]))
+	if !ok {
+		return nil, ErrAuthentication
+	}
+
+	return s.open(nil, ciphertext[keysetPrefixSize:], nil, ciphertext[:keysetPrefixSize], data)
+}
+
+// primaryKey returns the primary key and its ID, or an error if there is none
+// or it has been closed.
+func (k *Keyset) primaryKey() (uint32, *SIV, error) {
+	k.mu.RLock()
+	id, s := k.primary, k.keys[k.primary]
+	ok := k.hasPrimary
+	k.mu.RUnlock()
+
+	if !ok {
+		return 0, nil, errNoPrimary
+	}
+
+	if s.closed.Load() {
+		return 0, nil, ErrClosed
+	}
+	return id, s, nil
+}
+
+// key returns the key with the given ID, and whether it is in the keyset.
+func (k *Keyset) key(id uint32) (*SIV, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	s, ok := k.keys[id]
+	return s, ok
+}
@@ -0,0 +1,118 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"testing"
+)
+
+func newKeysetKey(t *testing.T, b byte) *SIV {
+	aead, err := New(bytes.Repeat([]byte{b}, 32), aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aead
+}
+
+func TestKeysetRotation(t *testing.T) {
+	plaintext := []byte("hello, world")
+	data := []byte("data")
+
+	k := NewKeyset()
+	if _, err := k.Seal(plaintext, data); err != errNoPrimary {
+		t.Errorf("Seal error without a primary key was %v, but expected %v", err, errNoPrimary)
+	}
+
+	if err := k.Add(1, newKeysetKey(t, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.SetPrimary(1); err != nil {
+		t.Fatal(err)
+	}
+
+	old, err := k.Seal(plaintext, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []byte{1, 0, 0, 0, 1}; !bytes.Equal(old[:5], expected) {
+		t.Errorf("Prefix was %x, but expected %x", old[:5], expected)
+	}
+
+	// rotate to a new primary key
+	if err := k.Add(2, newKeysetKey(t, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.SetPrimary(2); err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := k.Seal(plaintext, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id, _ := k.Primary(); id != 2 {
+		t.Errorf("Primary key was %d, but expected 2", id)
+	}
+
+	for _, c := range [][]byte{old, current} {
+		actual, err := k.Open(c, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual, plaintext) {
+			t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+		}
+	}
+
+	if err := k.Remove(2); err != errPrimary {
+		t.Errorf("Error removing the primary key was %v, but expected %v", err, errPrimary)
+	}
+
+	if err := k.Remove(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := k.Open(old, data); err != ErrAuthentication {
+		t.Errorf("Error opening with a removed key was %v, but expected %v", err, ErrAuthentication)
+	}
+}
+
+func TestKeysetErrors(t *testing.T) {
+	k := NewKeyset()
+	_ = k.Add(1, newKeysetKey(t, 1))
+	_ = k.Add(2, newKeysetKey(t, 2))
+	_ = k.SetPrimary(1)
+
+	if err := k.Add(1, newKeysetKey(t, 3)); err != errKeyID {
+		t.Errorf("Error adding a duplicate ID was %v, but expected %v", err, errKeyID)
+	}
+
+	if err := k.SetPrimary(3); err != errNoKey {
+		t.Errorf("Error setting an unknown primary was %v, but expected %v", err, errNoKey)
+	}
+
+	if err := k.Remove(3); err != errNoKey {
+		t.Errorf("Error removing an unknown key was %v, but expected %v", err, errNoKey)
+	}
+
+	ciphertext, err := k.Seal([]byte("hello, world"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the prefix is authenticated, so naming another key or version fails
+	relabelled := append([]byte(nil), ciphertext...)
+	relabelled[4] = 2
+
+	versioned := append([]byte(nil), ciphertext...)
+	versioned[0] = 2
+
+	for _, c := range [][]byte{nil, ciphertext[:4], ciphertext[:5], relabelled, versioned} {
+		if _, err := k.Open(c, nil); err != ErrAuthentication {
+			t.Errorf("Error opening %x was %v, but expected %v", c, err, ErrAuthentication)
+		}
+	}
+}
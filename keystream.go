@@ -0,0 +1,57 @@
+package siv
+
+import (
+	"crypto/cipher"
+	"errors"
+)
+
+var (
+	errTagSize = errors.New("invalid tag size")
+)
+
+// KeyStream returns the CTR stream Seal uses to encrypt a plaintext whose
+// synthetic IV is tag, with the counter masked as it would be for Seal. It is
+// intended for formats in which several related records share one tag and are
+// encrypted with disjoint ranges of its keystream.
+//
+// The caller is responsible for ensuring no position in the keystream is used
+// for more than one record: XORing two plaintexts with the same keystream
+// bytes reveals their XOR. Neither KeyStream nor the stream it returns provide
+// any authentication.
+func (s *SIV) KeyStream(tag []byte) (cipher.Stream, error) {
+	if s.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	if len(tag) != s.tagSize() {
+		return nil, errTagSize
+	}
+
+	return s.stream(tag), nil
+}
+
+// SealWithTag encrypts plaintext using a synthetic IV the caller has already
+// computed, returning the same output as Seal would without running S2V again.
+// The caller is responsible for tag being the S2V of the intended associated
+// data and plaintext: SealWithTag cannot check it, and a ciphertext sealed with
+// any other tag will fail to open.
+func (s *SIV) SealWithTag(tag, plaintext []byte) ([]byte, error) {
+	if s.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	if len(tag) != s.tagSize() {
+		return nil, errTagSize
+	}
+
+	sc := s.getScratch()
+	defer s.putScratch(sc)
+
+	out := make([]byte, s.Overhead()+len(plaintext))
+	ciphertext := out[s.Overhead():]
+	copy(ciphertext, plaintext)
+	copy(out[copy(out, s.magic):], tag)
+	s.xorKeyStream(sc, ciphertext, tag)
+
+	return out, nil
+}
@@ -0,0 +1,96 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stripe/siv-go/internal/cmac"
+)
+
+func TestKeyStream(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	plaintext := make([]byte, 100)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+	tag, body := ciphertext[:16], ciphertext[16:]
+
+	for _, r := range [][2]int{{0, 100}, {0, 14}, {32, 64}, {17, 50}, {99, 100}} {
+		stream, err := aead.KeyStream(tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// skip to the start of the range
+		skip := make([]byte, r[0])
+		stream.XORKeyStream(skip, skip)
+
+		actual := make([]byte, r[1]-r[0])
+		stream.XORKeyStream(actual, plaintext[r[0]:r[1]])
+
+		if expected := body[r[0]:r[1]]; !bytes.Equal(actual, expected) {
+			t.Errorf("Bytes %d to %d were %x, but expected %x", r[0], r[1], actual, expected)
+		}
+	}
+}
+
+func TestKeyStreamTagSize(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 8, 15, 17} {
+		if _, err := aead.KeyStream(make([]byte, n)); err != errTagSize {
+			t.Errorf("Error for a %d-byte tag was %v, but expected %v", n, err, errTagSize)
+		}
+	}
+
+	_ = aead.Close()
+
+	if _, err := aead.KeyStream(make([]byte, 16)); err != ErrClosed {
+		t.Errorf("Error after Close was %v, but expected %v", err, ErrClosed)
+	}
+}
+
+func TestSealWithTag(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, aes.NewCipher, WithMagic([]byte("SIV1")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 14, 16, 100, 1000} {
+		plaintext := bytes.Repeat([]byte{byte(n)}, n)
+		k1, k2 := aead.subkeys()
+		tag := s2v(cmac.New(aead.mac, k1, k2), []byte("SIV1"), data, plaintext)
+
+		actual, err := aead.SealWithTag(tag, plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := aead.Seal(nil, nil, plaintext, data); !bytes.Equal(actual, expected) {
+			t.Errorf("Ciphertext of %d bytes was %x, but expected %x", n, actual, expected)
+		}
+	}
+
+	if _, err := aead.SealWithTag(make([]byte, 15), nil); err != errTagSize {
+		t.Errorf("Error for a short tag was %v, but expected %v", err, errTagSize)
+	}
+}
@@ -0,0 +1,42 @@
+package siv
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// CanonicalAD returns a canonical encoding of kv for use as associated data:
+// for each key in sorted order, the key and then its value, each preceded by
+// its length as an 8-byte big-endian integer. Equal maps always give the same
+// encoding, whatever the order in which they were built. The result is never
+// nil, so an empty map is still authenticated as an (empty) component.
+func CanonicalAD(kv map[string][]byte) []byte {
+	keys := make([]string, 0, len(kv))
+	n := 0
+	for k, v := range kv {
+		keys = append(keys, k)
+		n += 16 + len(k) + len(v)
+	}
+	sort.Strings(keys)
+
+	data := make([]byte, 0, n)
+	for _, k := range keys {
+		data = binary.BigEndian.AppendUint64(data, uint64(len(k)))
+		data = append(data, k...)
+		data = binary.BigEndian.AppendUint64(data, uint64(len(kv[k])))
+		data = append(data, kv[k]...)
+	}
+
+	return data
+}
+
+// SealKV is like Seal with no nonce, but authenticates the canonical encoding
+// of kv given by CanonicalAD as its associated data.
+func (s *SIV) SealKV(plaintext []byte, kv map[string][]byte) []byte {
+	return s.mustSeal(nil, plaintext, nil, CanonicalAD(kv))
+}
+
+// OpenKV opens a ciphertext sealed by SealKV with an equal map.
+func (s *SIV) OpenKV(ciphertext []byte, kv map[string][]byte) ([]byte, error) {
+	return s.open(nil, ciphertext, nil, CanonicalAD(kv))
+}
@@ -0,0 +1,72 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestCanonicalAD(t *testing.T) {
+	expected, _ := hex.DecodeString("0000000000000001610000000000000002787900000000000000016200000000000000017a")
+
+	if actual := CanonicalAD(map[string][]byte{"b": []byte("z"), "a": []byte("xy")}); !bytes.Equal(actual, expected) {
+		t.Errorf("Encoding was %x, but expected %x", actual, expected)
+	}
+
+	if actual := CanonicalAD(nil); actual == nil || len(actual) != 0 {
+		t.Errorf("Encoding of an empty map was %#v, but expected an empty slice", actual)
+	}
+
+	// the lengths keep keys and values from running into one another
+	a := CanonicalAD(map[string][]byte{"ab": []byte("c")})
+	b := CanonicalAD(map[string][]byte{"a": []byte("bc")})
+	if bytes.Equal(a, b) {
+		t.Errorf("Different maps had the same encoding %x", a)
+	}
+}
+
+func TestSealKV(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []string{"tenant", "table", "column", "version", "region"}
+
+	forward := make(map[string][]byte)
+	for _, k := range keys {
+		forward[k] = []byte(k + " value")
+	}
+
+	reverse := make(map[string][]byte)
+	for i := len(keys) - 1; i >= 0; i-- {
+		reverse[keys[i]] = []byte(keys[i] + " value")
+	}
+
+	ciphertext := aead.SealKV(plaintext, forward)
+	if expected := aead.SealKV(plaintext, reverse); !bytes.Equal(ciphertext, expected) {
+		t.Errorf("Ciphertext was %x, but expected %x", ciphertext, expected)
+	}
+
+	actual, err := aead.OpenKV(ciphertext, reverse)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+
+	reverse["shard"] = []byte("7")
+	if v := aead.SealKV(plaintext, reverse); bytes.Equal(v[:16], ciphertext[:16]) {
+		t.Errorf("Adding a key did not change the tag %x", v[:16])
+	}
+
+	if actual, err := aead.OpenKV(ciphertext, reverse); err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v (plaintext %x)", err, ErrAuthentication, actual)
+	}
+}
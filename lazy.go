@@ -0,0 +1,41 @@
+package siv
+
+// SealLazyAD is like Seal with no nonce, but takes a function computing the
+// associated data, which is called exactly once, only once sealing is certain
+// to go ahead.
+func (s *SIV) SealLazyAD(plaintext []byte, adFunc func() []byte) []byte {
+	if err := s.checkNonce(nil); err != nil {
+		panic("siv: " + err.Error())
+	}
+
+	if s.closed.Load() {
+		panic("siv: Seal called after Close")
+	}
+
+	return s.mustSeal(nil, plaintext, nil, adFunc())
+}
+
+// OpenLazyAD opens a ciphertext as Open does with no nonce, taking a function
+// computing the associated data. The function is only called if ciphertext
+// passes the checks which need no associated data, so it is not called for a
+// ciphertext which is too short, too long or lacks the configured magic, and
+// is called at most once.
+func (s *SIV) OpenLazyAD(ciphertext []byte, adFunc func() []byte) ([]byte, error) {
+	if err := s.checkNonce(nil); err != nil {
+		return nil, err
+	}
+
+	if s.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	if !s.Valid(ciphertext) {
+		return nil, ErrAuthentication
+	}
+
+	if s.tooLong(ciphertext) {
+		return nil, errMaxOpenLen
+	}
+
+	return s.open(nil, ciphertext, nil, adFunc())
+}
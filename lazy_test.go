@@ -0,0 +1,66 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSealLazyAD(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	expected, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	adFunc := func() []byte {
+		calls++
+		return data
+	}
+
+	ciphertext := aead.SealLazyAD(plaintext, adFunc)
+	if !bytes.Equal(ciphertext, expected) {
+		t.Errorf("Ciphertext was %x, but expected %x", ciphertext, expected)
+	}
+
+	if calls != 1 {
+		t.Errorf("adFunc was called %d times by SealLazyAD, but expected 1", calls)
+	}
+
+	actual, err := aead.OpenLazyAD(ciphertext, adFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+
+	if calls != 2 {
+		t.Errorf("adFunc was called %d times by OpenLazyAD, but expected 1", calls-1)
+	}
+}
+
+func TestOpenLazyADEarlyReturn(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adFunc := func() []byte {
+		t.Error("adFunc was called for a ciphertext which was too short")
+		return nil
+	}
+
+	if _, err := aead.OpenLazyAD(make([]byte, 15), adFunc); err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v", err, ErrAuthentication)
+	}
+}
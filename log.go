@@ -0,0 +1,154 @@
+package siv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// maxLogFieldSize bounds the length of each field of a log entry that Verify
+// will accept, and with it the memory an untrusted log can make it allocate.
+const maxLogFieldSize = 16 * 1024 * 1024
+
+var (
+	errLogEntry = errors.New("invalid log entry")
+)
+
+// A SealedLog writes an append-only log of sealed entries. Each entry is
+// sealed with its associated data and the synthetic IV of the entry before it
+// as separate associated data components, chaining the entries so that
+// removing, reordering or inserting entries is detected by Verify. A SealedLog
+// is not safe for concurrent use.
+//
+// Each entry is written as the associated data and then the sealed entry, each
+// preceded by its length as a 4-byte big-endian integer. The associated data
+// is not encrypted.
+type SealedLog struct {
+	s    *SIV
+	w    io.Writer
+	head []byte
+	buf  []byte
+}
+
+// NewSealedLog returns a SealedLog which appends entries to w, which should be
+// empty.
+func (s *SIV) NewSealedLog(w io.Writer) *SealedLog {
+	return &SealedLog{
+		s:    s,
+		w:    w,
+		head: []byte{},
+	}
+}
+
+// Append seals entry, chained to the previous entry, and writes it to the log
+// along with data.
+func (l *SealedLog) Append(entry, data []byte) error {
+	if l.s.closed.Load() {
+		return ErrClosed
+	}
+
+	if len(data) > maxLogFieldSize || len(entry)+l.s.Overhead() > maxLogFieldSize {
+		return errLogEntry
+	}
+
+	buf := binary.BigEndian.AppendUint32(l.buf[:0], uint32(len(data)))
+	buf = append(buf, data...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(entry)+l.s.Overhead()))
+	buf, err := l.s.seal(buf, entry, nil, nonNil(data), l.head)
+	if err != nil {
+		return err
+	}
+	l.buf = buf
+
+	if _, err := l.w.Write(buf); err != nil {
+		return err
+	}
+
+	l.head = append(l.head[:0], l.s.tag(buf[len(buf)-len(entry)-l.s.Overhead():])...)
+	return nil
+}
+
+// Head returns the synthetic IV of the most recently appended entry, which
+// identifies the whole log up to and including it. It is empty if no entries
+// have been appended.
+func (l *SealedLog) Head() []byte {
+	return append([]byte{}, l.head...)
+}
+
+// Verify reads a log from r and checks that every entry is authentic and
+// follows the one before it. If any entries have been appended to l, it also
+// checks that the log ends with the most recent of them, so that a log which
+// has been truncated is also detected. Verify returns ErrAuthentication if the
+// chain is broken.
+func (l *SealedLog) Verify(r io.Reader) error {
+	prev := []byte{}
+	var data, sealed, plaintext []byte
+
+	for {
+		var err error
+		data, err = readLogField(r, data, true)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		sealed, err = readLogField(r, sealed, false)
+		if err != nil {
+			return err
+		}
+
+		if !l.s.Valid(sealed) {
+			return ErrAuthentication
+		}
+
+		plaintext, err = l.s.open(plaintext[:0], sealed, nil, nonNil(data), prev)
+		if err != nil {
+			return err
+		}
+
+		prev = append(prev[:0], l.s.tag(sealed)...)
+	}
+
+	if len(l.head) > 0 && !bytes.Equal(prev, l.head) {
+		return ErrAuthentication
+	}
+
+	return nil
+}
+
+// readLogField reads a length-prefixed field of a log entry into buf. It
+// returns io.EOF only if first is set and r is empty.
+func readLogField(r io.Reader, buf []byte, first bool) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF && first {
+			return nil, io.EOF
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errLogEntry
+		}
+		return nil, err
+	}
+
+	n := binary.BigEndian.Uint32(header[:])
+	if n > maxLogFieldSize {
+		return nil, errLogEntry
+	}
+
+	if cap(buf) < int(n) {
+		buf = make([]byte, n)
+	}
+	buf = buf[:n]
+
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errLogEntry
+		}
+		return nil, err
+	}
+
+	return buf, nil
+}
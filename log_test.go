@@ -0,0 +1,96 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func newTestLog(t *testing.T) (*SealedLog, *bytes.Buffer, [][]byte) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	log := aead.NewSealedLog(buf)
+
+	var entries [][]byte
+	for i, entry := range []string{"login alice", "read /etc/shadow", "", "logout alice"} {
+		start := buf.Len()
+		if err := log.Append([]byte(entry), []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, buf.Bytes()[start:])
+	}
+
+	return log, buf, entries
+}
+
+func TestSealedLog(t *testing.T) {
+	log, buf, entries := newTestLog(t)
+
+	if err := log.Verify(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+
+	last := entries[len(entries)-1]
+	if head, expected := log.Head(), last[9:25]; !bytes.Equal(head, expected) {
+		t.Errorf("Head was %x, but expected %x", head, expected)
+	}
+}
+
+func TestSealedLogTampered(t *testing.T) {
+	log, buf, entries := newTestLog(t)
+
+	join := func(entries ...[]byte) []byte {
+		return bytes.Join(entries, nil)
+	}
+
+	for name, b := range map[string][]byte{
+		"removed":       join(entries[0], entries[2], entries[3]),
+		"removed first": join(entries[1], entries[2], entries[3]),
+		"swapped":       join(entries[0], entries[2], entries[1], entries[3]),
+		"duplicated":    join(entries[0], entries[1], entries[1], entries[2], entries[3]),
+		"truncated":     join(entries[0], entries[1], entries[2]),
+		"empty":         nil,
+	} {
+		if err := log.Verify(bytes.NewReader(b)); err != ErrAuthentication {
+			t.Errorf("Verify of a %s log returned %v, but expected %v", name, err, ErrAuthentication)
+		}
+	}
+
+	tampered := append([]byte(nil), buf.Bytes()...)
+	tampered[len(entries[0])+4] ^= 1 // the second entry's associated data
+
+	if err := log.Verify(bytes.NewReader(tampered)); err != ErrAuthentication {
+		t.Errorf("Verify of a tampered log returned %v, but expected %v", err, ErrAuthentication)
+	}
+
+	if err := log.Verify(bytes.NewReader(buf.Bytes()[:buf.Len()-1])); err != errLogEntry {
+		t.Errorf("Verify of a cut off log returned %v, but expected %v", err, errLogEntry)
+	}
+}
+
+func TestSealedLogEmptyData(t *testing.T) {
+	aead, err := New(make([]byte, 32), aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, data := range [][]byte{nil, {}} {
+		buf := new(bytes.Buffer)
+		log := aead.NewSealedLog(buf)
+
+		if err := log.Append([]byte("entry"), data); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := log.Verify(bytes.NewReader(buf.Bytes())); err != nil {
+			t.Errorf("Verify of a log with data %#v returned %v, but expected nil", data, err)
+		}
+	}
+}
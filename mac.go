@@ -0,0 +1,60 @@
+package siv
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"hash"
+
+	"github.com/stripe/siv-go/internal/cmac"
+)
+
+// NewMAC returns a hash.Hash computing S2V over the bytes written to it as a
+// single associated data component. Its sum is the synthetic IV that Seal
+// would produce for an empty plaintext with everything written as the
+// associated data, so it can be used to authenticate data assembled
+// incrementally, for example with io.Copy. Only the MAC half of key is used.
+//
+// The hash.Hash interface only allows for a single component; use SealMulti
+// with an empty plaintext to MAC a vector of them.
+func NewMAC(key []byte, alg func([]byte) (cipher.Block, error)) (hash.Hash, error) {
+	s, err := New(key, alg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mac{
+		s: s,
+		h: cmac.New(s.mac, s.k1, s.k2),
+	}, nil
+}
+
+type mac struct {
+	s *SIV
+	h *cmac.CMAC
+}
+
+func (m *mac) Write(p []byte) (int, error) {
+	return m.h.Write(p)
+}
+
+func (m *mac) Sum(b []byte) []byte {
+	st := newS2V(cmac.New(m.s.mac, m.s.k1, m.s.k2))
+
+	// as s2vState.add, but leaving the running CMAC untouched
+	cmac.Dbl(st.d)
+	subtle.XORBytes(st.d, st.d, m.h.Sum(st.t[:0]))
+
+	return append(b, st.sum(nil)...)
+}
+
+func (m *mac) Reset() {
+	m.h.Reset()
+}
+
+func (m *mac) Size() int {
+	return m.h.Size()
+}
+
+func (m *mac) BlockSize() int {
+	return m.h.BlockSize()
+}
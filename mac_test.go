@@ -0,0 +1,57 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+func TestMAC(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := aead.Seal(nil, nil, nil, data)
+
+	h, err := NewMAC(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := io.Copy(h, &chunkReader{r: bytes.NewReader(data), n: 7}); err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := h.Sum(nil); !bytes.Equal(actual, expected) {
+		t.Errorf("MAC was %x, but expected %x", actual, expected)
+	}
+
+	// Sum does not change the state
+	if actual := h.Sum(nil); !bytes.Equal(actual, expected) {
+		t.Errorf("Second MAC was %x, but expected %x", actual, expected)
+	}
+
+	h.Reset()
+	_, _ = h.Write(data[:10])
+
+	if actual, expected := h.Sum(nil), aead.Seal(nil, nil, nil, data[:10]); !bytes.Equal(actual, expected) {
+		t.Errorf("MAC after Reset was %x, but expected %x", actual, expected)
+	}
+}
+
+func TestMACBadKeySize(t *testing.T) {
+	h, err := NewMAC(make([]byte, 16), aes.NewCipher)
+	if err == nil {
+		t.Fatalf("MAC returned instead of error: %v", h)
+	}
+}
@@ -0,0 +1,135 @@
+// Package miscreant mirrors the API of Miscreant's Go package on top of
+// package siv, so that services using Miscreant can migrate by changing an
+// import path. Keys are split as in RFC 5297 and Miscreant, the first half
+// keying S2V and the second CTR, and ciphertexts are interchangeable with
+// those of Miscreant's libraries in other languages.
+package miscreant
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+
+	siv "github.com/stripe/siv-go"
+)
+
+// MaxAssociatedDataItems is the most associated data components S2V allows.
+const MaxAssociatedDataItems = 126
+
+var (
+	// ErrKeySize is returned for keys other than 32 or 64 bytes.
+	ErrKeySize = errors.New("invalid key size: must be 32 or 64 bytes")
+
+	// ErrNotAuthentic is returned by Open for forged or corrupt ciphertexts.
+	// It is siv.ErrAuthentication.
+	ErrNotAuthentic = siv.ErrAuthentication
+
+	// ErrTooManyAssociatedDataItems is returned for more than
+	// MaxAssociatedDataItems associated data components.
+	ErrTooManyAssociatedDataItems = errors.New("too many associated data items")
+
+	errAlgorithm = errors.New("unknown algorithm: must be AES-SIV, AES-CMAC-SIV or AES-PMAC-SIV")
+	errNonceSize = errors.New("invalid nonce size")
+)
+
+// A Cipher is an AES-SIV or AES-PMAC-SIV instance.
+type Cipher struct {
+	s *siv.SIV
+}
+
+// NewAESCMACSIV returns an AES-SIV Cipher with a 32 or 64-byte key.
+func NewAESCMACSIV(key []byte) (*Cipher, error) {
+	return newCipher(key, siv.New)
+}
+
+// NewAESPMACSIV returns an AES-PMAC-SIV Cipher with a 32 or 64-byte key.
+func NewAESPMACSIV(key []byte) (*Cipher, error) {
+	return newCipher(key, siv.NewPMACSIV)
+}
+
+func newCipher(key []byte, newSIV func([]byte, func([]byte) (cipher.Block, error), ...siv.Option) (*siv.SIV, error)) (*Cipher, error) {
+	if len(key) != 32 && len(key) != 64 {
+		return nil, ErrKeySize
+	}
+
+	s, err := newSIV(key, aes.NewCipher)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{s: s}, nil
+}
+
+// Overhead returns the difference between ciphertext and plaintext lengths.
+func (c *Cipher) Overhead() int {
+	return c.s.Overhead()
+}
+
+// Seal appends the synthetic IV and the encryption of plaintext to dst,
+// authenticating each associated data component separately and in order.
+func (c *Cipher) Seal(dst, plaintext []byte, data ...[]byte) ([]byte, error) {
+	if len(data) > MaxAssociatedDataItems {
+		return nil, ErrTooManyAssociatedDataItems
+	}
+	return c.s.SealMulti(dst, plaintext, data...), nil
+}
+
+// Open opens a ciphertext sealed by Seal with the same associated data
+// components, appending the plaintext to dst.
+func (c *Cipher) Open(dst, ciphertext []byte, data ...[]byte) ([]byte, error) {
+	if len(data) > MaxAssociatedDataItems {
+		return nil, ErrTooManyAssociatedDataItems
+	}
+	return c.s.OpenMulti(dst, ciphertext, data...)
+}
+
+type aead struct {
+	c         *Cipher
+	nonceSize int
+}
+
+// NewAEAD returns a cipher.AEAD for the algorithm "AES-SIV" (or
+// "AES-CMAC-SIV") or "AES-PMAC-SIV" with nonces of nonceSize bytes, which are
+// authenticated as the final associated data component, after the data.
+func NewAEAD(alg string, key []byte, nonceSize int) (cipher.AEAD, error) {
+	var c *Cipher
+	var err error
+
+	switch alg {
+	case "AES-SIV", "AES-CMAC-SIV":
+		c, err = NewAESCMACSIV(key)
+	case "AES-PMAC-SIV":
+		c, err = NewAESPMACSIV(key)
+	default:
+		return nil, errAlgorithm
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &aead{c: c, nonceSize: nonceSize}, nil
+}
+
+func (a *aead) NonceSize() int {
+	return a.nonceSize
+}
+
+func (a *aead) Overhead() int {
+	return a.c.Overhead()
+}
+
+func (a *aead) Seal(dst, nonce, plaintext, data []byte) []byte {
+	if len(nonce) != a.nonceSize {
+		panic("miscreant: invalid nonce size")
+	}
+
+	out, _ := a.c.Seal(dst, plaintext, data, nonce)
+	return out
+}
+
+func (a *aead) Open(dst, nonce, ciphertext, data []byte) ([]byte, error) {
+	if len(nonce) != a.nonceSize {
+		return nil, errNonceSize
+	}
+
+	return a.c.Open(dst, ciphertext, data, nonce)
+}
@@ -0,0 +1,150 @@
+package miscreant
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestCipherVectors(t *testing.T) {
+	for _, v := range []struct {
+		name           string
+		newCipher      func([]byte) (*Cipher, error)
+		key, plaintext string
+		ad             []string
+		ciphertext     string
+	}{
+		{
+			// https://tools.ietf.org/html/rfc5297#appendix-A.1
+			name:       "AES-SIV A.1",
+			newCipher:  NewAESCMACSIV,
+			key:        "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+			ad:         []string{"101112131415161718191a1b1c1d1e1f2021222324252627"},
+			plaintext:  "112233445566778899aabbccddee",
+			ciphertext: "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c",
+		},
+		{
+			// https://tools.ietf.org/html/rfc5297#appendix-A.2
+			name:      "AES-SIV A.2",
+			newCipher: NewAESCMACSIV,
+			key:       "7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f",
+			ad: []string{
+				"00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100",
+				"102030405060708090a0",
+				"09f911029d74e35bd84156c5635688c0",
+			},
+			plaintext:  "7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553",
+			ciphertext: "7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17dba77ceb094fa663b7a3f748ba8af829ea64ad544a272e9c485b62a3fd5c0d",
+		},
+		{
+			// from the Miscreant test suite
+			name:       "AES-PMAC-SIV A.1",
+			newCipher:  NewAESPMACSIV,
+			key:        "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+			ad:         []string{"101112131415161718191a1b1c1d1e1f2021222324252627"},
+			plaintext:  "112233445566778899aabbccddee",
+			ciphertext: "8c4b814216140fc9b34a41716aa61633ea66abe16b2f6e4bceeda6e9077f",
+		},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			key, _ := hex.DecodeString(v.key)
+			plaintext, _ := hex.DecodeString(v.plaintext)
+			expected, _ := hex.DecodeString(v.ciphertext)
+
+			var ad [][]byte
+			for _, s := range v.ad {
+				b, _ := hex.DecodeString(s)
+				ad = append(ad, b)
+			}
+
+			c, err := v.newCipher(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ciphertext, err := c.Seal(nil, plaintext, ad...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(ciphertext, expected) {
+				t.Errorf("Ciphertext was %x, but expected %x", ciphertext, expected)
+			}
+
+			actual, err := c.Open(nil, ciphertext, ad...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(actual, plaintext) {
+				t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+			}
+
+			ciphertext[0] ^= 1
+			if _, err := c.Open(nil, ciphertext, ad...); err != ErrNotAuthentic {
+				t.Errorf("Error was %v, but expected %v", err, ErrNotAuthentic)
+			}
+		})
+	}
+}
+
+func TestAEAD(t *testing.T) {
+	// RFC 5297 A.2 with its first component as the data and its last as the
+	// nonce
+	key, _ := hex.DecodeString("7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f")
+	data, _ := hex.DecodeString("00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100")
+	nonce, _ := hex.DecodeString("09f911029d74e35bd84156c5635688c0")
+	plaintext, _ := hex.DecodeString("7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553")
+
+	for _, alg := range []string{"AES-SIV", "AES-PMAC-SIV"} {
+		a, err := NewAEAD(alg, key, len(nonce))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var c *Cipher
+		if alg == "AES-SIV" {
+			c, _ = NewAESCMACSIV(key)
+		} else {
+			c, _ = NewAESPMACSIV(key)
+		}
+
+		ciphertext := a.Seal(nil, nonce, plaintext, data)
+		expected, _ := c.Seal(nil, plaintext, data, nonce)
+		if !bytes.Equal(ciphertext, expected) {
+			t.Errorf("%s ciphertext was %x, but expected %x", alg, ciphertext, expected)
+		}
+
+		actual, err := a.Open(nil, nonce, ciphertext, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual, plaintext) {
+			t.Errorf("%s plaintext was %x, but expected %x", alg, actual, plaintext)
+		}
+
+		if _, err := a.Open(nil, nonce[1:], ciphertext, data); err != errNonceSize {
+			t.Errorf("%s error for a short nonce was %v, but expected %v", alg, err, errNonceSize)
+		}
+	}
+
+	if _, err := NewAEAD("AES-GCM", key, 16); err != errAlgorithm {
+		t.Errorf("Error for an unknown algorithm was %v, but expected %v", err, errAlgorithm)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	if _, err := NewAESCMACSIV(make([]byte, 48)); err != ErrKeySize {
+		t.Errorf("Error for a 48-byte key was %v, but expected %v", err, ErrKeySize)
+	}
+
+	c, _ := NewAESPMACSIV(make([]byte, 32))
+	if _, err := c.Seal(nil, nil, make([][]byte, MaxAssociatedDataItems+1)...); err != ErrTooManyAssociatedDataItems {
+		t.Errorf("Error for too many components was %v, but expected %v", err, ErrTooManyAssociatedDataItems)
+	}
+
+	if _, err := c.Seal(nil, nil, make([][]byte, MaxAssociatedDataItems)...); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,46 @@
+package siv
+
+// SealMulti is like Seal, but authenticates a vector of associated data
+// components as described in RFC 5297, rather than a single one. Each
+// component is bound separately and in order, with a nil component treated as
+// empty, so moving bytes between components or reordering them changes the
+// ciphertext; there is no need for a separator or length encoding.
+func (s *SIV) SealMulti(dst, plaintext []byte, ad ...[]byte) []byte {
+	return s.mustSeal(dst, plaintext, nil, components(ad)...)
+}
+
+// OpenMulti opens a ciphertext sealed by SealMulti with the same associated
+// data components.
+func (s *SIV) OpenMulti(dst, ciphertext []byte, ad ...[]byte) ([]byte, error) {
+	return s.open(dst, ciphertext, nil, components(ad)...)
+}
+
+// CanonicalizeLegacy returns the concatenation of parts, matching schemes which
+// authenticated several fields by joining them into one associated data
+// string. Passing the result to Open as data opens ciphertexts sealed that
+// way. An empty result is non-nil, and so still authenticated as an (empty)
+// component. Unlike SealMulti it does not bind the boundaries between parts,
+// so new ciphertexts should use SealMulti instead.
+func CanonicalizeLegacy(parts ...[]byte) []byte {
+	var data []byte
+	for _, v := range parts {
+		data = append(data, v...)
+	}
+	return nonNil(data)
+}
+
+// components replaces any nil components of ad with empty ones, so that they
+// are included rather than skipped.
+func components(ad [][]byte) [][]byte {
+	for i, v := range ad {
+		if v == nil {
+			c := make([][]byte, len(ad))
+			copy(c, ad)
+			for j := range c[i:] {
+				c[i+j] = nonNil(c[i+j])
+			}
+			return c
+		}
+	}
+	return ad
+}
@@ -0,0 +1,130 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSealMulti(t *testing.T) {
+	// https://tools.ietf.org/html/rfc5297#appendix-A.2
+	key, _ := hex.DecodeString("7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f")
+	ad1, _ := hex.DecodeString("00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100")
+	ad2, _ := hex.DecodeString("102030405060708090a0")
+	nonce, _ := hex.DecodeString("09f911029d74e35bd84156c5635688c0")
+	plaintext, _ := hex.DecodeString("7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553")
+	ciphertext, _ := hex.DecodeString("7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17dba77ceb094fa663b7a3f748ba8af829ea64ad544a272e9c485b62a3fd5c0d")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := aead.SealMulti(nil, plaintext, ad1, ad2, nonce)
+	if !bytes.Equal(actual, ciphertext) {
+		t.Errorf("Ciphertext was %x, but expected %x", actual, ciphertext)
+	}
+
+	actual, err = aead.OpenMulti(nil, ciphertext, ad1, ad2, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+}
+
+func TestSealMultiNilComponent(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := aead.SealMulti(nil, plaintext, nil, []byte("x"))
+	b := aead.SealMulti(nil, plaintext, []byte{}, []byte("x"))
+	c := aead.SealMulti(nil, plaintext, []byte("x"))
+
+	if !bytes.Equal(a, b) {
+		t.Errorf("Nil component sealed as %x, but empty component as %x", a, b)
+	}
+
+	if bytes.Equal(a, c) {
+		t.Errorf("Nil component was skipped: %x", a)
+	}
+}
+
+func TestSealMultiBoundaries(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.SealMulti(nil, plaintext, []byte("key-1"), []byte("v2"))
+
+	for _, ad := range [][][]byte{
+		{[]byte("key-1v"), []byte("2")},
+		{[]byte("v2"), []byte("key-1")},
+		{[]byte("key-1v2")},
+		{[]byte("key-1"), []byte("v2"), []byte{}},
+	} {
+		if _, err := aead.OpenMulti(nil, ciphertext, ad...); err != ErrAuthentication {
+			t.Errorf("OpenMulti with %q returned %v", ad, err)
+		}
+	}
+}
+
+func TestCanonicalizeLegacy(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	parts := [][]byte{[]byte("key-id"), []byte("v1"), []byte("recipient")}
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the legacy scheme concatenated its fields into one string
+	var legacy []byte
+	for _, v := range parts {
+		legacy = append(legacy, v...)
+	}
+	ciphertext := aead.Seal(nil, nil, plaintext, legacy)
+
+	actual, err := aead.Open(nil, nil, ciphertext, CanonicalizeLegacy(parts...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+
+	actual, err = aead.OpenMulti(nil, ciphertext, parts...)
+	if err == nil {
+		t.Fatalf("Plaintext returned instead of error: %x", actual)
+	}
+}
+
+func TestCanonicalizeLegacyEmpty(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, []byte{})
+
+	if _, err := aead.Open(nil, nil, ciphertext, CanonicalizeLegacy()); err != nil {
+		t.Fatal(err)
+	}
+}
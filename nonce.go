@@ -0,0 +1,65 @@
+package siv
+
+import (
+	"crypto/cipher"
+	"errors"
+)
+
+var (
+	errNonceSize = errors.New("invalid nonce size")
+)
+
+// NewWithNonceSize is like New, but returns a nonce-based AEAD for use where
+// generic code expects a fixed NonceSize, such as wrappers which generate a
+// random nonce for any cipher.AEAD. It is New with WithNonceSize, except that
+// nonceSize must be positive: Seal and Open require nonces of exactly
+// nonceSize bytes and authenticate them as the final associated data
+// component, as in the nonce-based usage of RFC 5297 section 3.
+func NewWithNonceSize(key []byte, alg func([]byte) (cipher.Block, error), nonceSize int, opts ...Option) (*SIV, error) {
+	if nonceSize <= 0 {
+		return nil, errNonceSize
+	}
+
+	return New(key, alg, append(opts[:len(opts):len(opts)], WithNonceSize(nonceSize))...)
+}
+
+// A Nonce is a nonce for SealNonce and OpenNonce. It is authenticated as the
+// final associated data component, exactly as the nonce given to Seal is.
+type Nonce []byte
+
+// SealNonce is like Seal, but returns an error unless len(nonce) is the size
+// configured with WithNonceSize. A nil nonce is only accepted when that size
+// is 0, in which case no nonce is authenticated; an empty but non-nil one is
+// rejected, as it would be authenticated as an empty component.
+func (s *SIV) SealNonce(nonce Nonce, plaintext, data []byte) ([]byte, error) {
+	if err := s.checkNonceSize(nonce); err != nil {
+		return nil, err
+	}
+
+	if s.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	return s.seal(nil, plaintext, nonce, data)
+}
+
+// OpenNonce is like Open, but returns an error unless len(nonce) is the size
+// configured with WithNonceSize, with the same treatment of empty nonces as
+// SealNonce.
+func (s *SIV) OpenNonce(nonce Nonce, ciphertext, data []byte) ([]byte, error) {
+	if err := s.checkNonceSize(nonce); err != nil {
+		return nil, err
+	}
+
+	return s.open(nil, ciphertext, nonce, data)
+}
+
+// checkNonceSize returns errNonceSize unless len(nonce) is the configured
+// size, or if nonce is empty but not nil.
+func (s *SIV) checkNonceSize(nonce Nonce) error {
+	if len(nonce) != s.nonceSize || nonce != nil && len(nonce) == 0 {
+		return errNonceSize
+	}
+
+	return nil
+}
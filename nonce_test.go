@@ -0,0 +1,241 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSealNonce(t *testing.T) {
+	key, _ := hex.DecodeString("7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f")
+	data, _ := hex.DecodeString("00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100")
+	nonce, _ := hex.DecodeString("09f911029d74e35bd84156c5635688c0")
+	plaintext, _ := hex.DecodeString("7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553")
+
+	aead, err := New(key, aes.NewCipher, WithNonceSize(len(nonce)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := aead.NonceSize(); v != len(nonce) {
+		t.Errorf("NonceSize was %d, but expected %d", v, len(nonce))
+	}
+
+	ciphertext, err := aead.SealNonce(nonce, plaintext, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := aead.Seal(nil, nonce, plaintext, data)
+	if !bytes.Equal(ciphertext, expected) {
+		t.Errorf("Ciphertext was %x, but expected %x", ciphertext, expected)
+	}
+
+	actual, err := aead.OpenNonce(nonce, ciphertext, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+}
+
+func TestSealNonceSize(t *testing.T) {
+	key, _ := hex.DecodeString("7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f")
+	plaintext := []byte("plaintext")
+
+	aead, err := New(key, aes.NewCipher, WithNonceSize(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := aead.SealNonce(make(Nonce, 16), plaintext, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 8, 15, 17, 32} {
+		var nonce Nonce
+		if n > 0 {
+			nonce = make(Nonce, n)
+		}
+
+		if _, err := aead.SealNonce(nonce, plaintext, nil); err != errNonceSize {
+			t.Errorf("SealNonce with a %d-byte nonce returned %v, but expected %v", n, err, errNonceSize)
+		}
+
+		if _, err := aead.OpenNonce(nonce, ciphertext, nil); err != errNonceSize {
+			t.Errorf("OpenNonce with a %d-byte nonce returned %v, but expected %v", n, err, errNonceSize)
+		}
+	}
+
+	unset, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := unset.SealNonce(make(Nonce, 16), plaintext, nil); err != errNonceSize {
+		t.Errorf("SealNonce without a nonce size returned %v, but expected %v", err, errNonceSize)
+	}
+
+	if _, err := unset.SealNonce(nil, plaintext, nil); err != nil {
+		t.Errorf("SealNonce with no nonce returned %v", err)
+	}
+
+	// an empty nonce would be authenticated where no nonce is not
+	if _, err := unset.SealNonce(Nonce{}, plaintext, nil); err != errNonceSize {
+		t.Errorf("SealNonce with an empty nonce returned %v, but expected %v", err, errNonceSize)
+	}
+
+	if _, err := unset.OpenNonce(Nonce{}, unset.Seal(nil, nil, plaintext, nil), nil); err != errNonceSize {
+		t.Errorf("OpenNonce with an empty nonce returned %v, but expected %v", err, errNonceSize)
+	}
+
+	negative, err := New(key, aes.NewCipher, WithNonceSize(-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := negative.NonceSize(); n != 0 {
+		t.Errorf("NonceSize for a negative size was %d, but expected 0", n)
+	}
+}
+
+func TestNewWithNonceSize(t *testing.T) {
+	// https://tools.ietf.org/html/rfc5297#appendix-A.2, with the associated
+	// data components joined into one
+	key, _ := hex.DecodeString("7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f")
+	data, _ := hex.DecodeString("00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100")
+	nonce, _ := hex.DecodeString("09f911029d74e35bd84156c5635688c0")
+	plaintext, _ := hex.DecodeString("7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553")
+
+	plain, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := plain.SealMulti(nil, plaintext, data, nonce)
+
+	sized, err := NewWithNonceSize(key, aes.NewCipher, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the option alone must behave exactly as the constructor does
+	option, err := New(key, aes.NewCipher, WithNonceSize(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, aead := range []*SIV{sized, option} {
+		if n := aead.NonceSize(); n != 16 {
+			t.Errorf("NonceSize was %d, but expected 16", n)
+		}
+
+		ciphertext := aead.Seal(nil, nonce, plaintext, data)
+		if !bytes.Equal(ciphertext, expected) {
+			t.Errorf("Ciphertext was %x, but expected %x", ciphertext, expected)
+		}
+
+		for _, n := range []int{0, 12, 15, 17} {
+			var wrong []byte
+			if n > 0 {
+				wrong = make([]byte, n)
+			}
+
+			if _, err := aead.Open(nil, wrong, ciphertext, data); err != errNonceSize {
+				t.Errorf("Open with a %d-byte nonce returned %v, but expected %v", n, err, errNonceSize)
+			}
+
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("Seal with a %d-byte nonce did not panic", n)
+					}
+				}()
+				aead.Seal(nil, wrong, plaintext, data)
+			}()
+		}
+	}
+
+	for _, n := range []int{-1, 0} {
+		if _, err := NewWithNonceSize(key, aes.NewCipher, n); err != errNonceSize {
+			t.Errorf("Error for nonce size %d was %v, but expected %v", n, err, errNonceSize)
+		}
+	}
+}
+
+func TestNonceSizeHelpers(t *testing.T) {
+	key := make([]byte, 32)
+	plain, _ := New(key, aes.NewCipher)
+	sized, _ := NewWithNonceSize(key, aes.NewCipher, 12)
+
+	plaintext := []byte("plaintext")
+	data := []byte("data")
+	ciphertext := plain.Seal(nil, nil, plaintext, data)
+
+	// methods which take no nonce cannot satisfy an AEAD which requires one
+	errs := map[string]func() error{
+		"OpenMulti": func() error {
+			_, err := sized.OpenMulti(nil, ciphertext, data)
+			return err
+		},
+		"OpenWithFooter": func() error {
+			_, err := sized.OpenWithFooter(ciphertext, data, nil)
+			return err
+		},
+		"OpenBatch": func() error {
+			_, err := sized.OpenBatch([][]byte{ciphertext}, data)
+			return err
+		},
+		"SealTo": func() error {
+			return sized.SealTo(new(bytes.Buffer), plaintext, data)
+		},
+		"SealAt": func() error {
+			_, err := sized.SealAt(plaintext, data, 0)
+			return err
+		},
+		"ExpectTag": func() error {
+			return sized.ExpectTag(plaintext, data, plain.tag(ciphertext))
+		},
+		"StreamSeal": func() error {
+			return sized.StreamSeal(new(bytes.Buffer), bytes.NewReader(plaintext), data)
+		},
+		"VerifyStream": func() error {
+			return sized.VerifyStream(bytes.NewReader(ciphertext), data)
+		},
+		"SealedLog.Append": func() error {
+			return sized.NewSealedLog(new(bytes.Buffer)).Append(plaintext, data)
+		},
+	}
+
+	for name, f := range errs {
+		if err := f(); err != errNonceSize {
+			t.Errorf("%s error was %v, but expected %v", name, err, errNonceSize)
+		}
+	}
+
+	panics := map[string]func(){
+		"SealMulti": func() {
+			sized.SealMulti(nil, plaintext, data)
+		},
+		"SealWithFooter": func() {
+			sized.SealWithFooter(plaintext, data, nil)
+		},
+		"SealBatch": func() {
+			sized.SealBatch([][]byte{plaintext}, data)
+		},
+	}
+
+	for name, f := range panics {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s did not panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,86 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSealAt(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := aead.SealAt(plaintext, data, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the ciphertext is the plaintext XORed with the keystream from the fourth
+	// block onwards
+	block, _ := aes.NewCipher(key[16:])
+	v := ciphertext[:aead.Overhead()]
+	keystream := make([]byte, 3*aes.BlockSize+len(plaintext))
+	cipher.NewCTR(block, ctr(v)).XORKeyStream(keystream, keystream)
+
+	expected := make([]byte, len(plaintext))
+	for i := range expected {
+		expected[i] = plaintext[i] ^ keystream[3*aes.BlockSize+i]
+	}
+
+	if actual := ciphertext[aead.Overhead():]; !bytes.Equal(actual, expected) {
+		t.Errorf("Ciphertext was %x, but expected %x", actual, expected)
+	}
+
+	actual, err := aead.OpenAt(ciphertext, data, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+
+	actual, err = aead.OpenAt(ciphertext, data, 2)
+	if err == nil {
+		t.Fatalf("Plaintext returned instead of error: %x", actual)
+	}
+}
+
+func TestSealAtZeroOffset(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	ciphertext, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := aead.SealAt(plaintext, data, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, ciphertext) {
+		t.Errorf("Ciphertext was %x, but expected %x", actual, ciphertext)
+	}
+}
+
+func TestAddCounter(t *testing.T) {
+	q, _ := hex.DecodeString("000000000000000000000000fffffffe")
+	expected, _ := hex.DecodeString("00000000000000000000000100000101")
+
+	addCounter(q, 0x103)
+	if !bytes.Equal(q, expected) {
+		t.Errorf("Counter was %x, but expected %x", q, expected)
+	}
+}
@@ -0,0 +1,107 @@
+package siv
+
+import (
+	"crypto/cipher"
+)
+
+// An Option configures an SIV AEAD.
+type Option func(*SIV)
+
+// WithCTR replaces the construction of the CTR stream used for encryption.
+// newCTR is given the encryption block cipher and the synthetic IV, and is
+// responsible for any masking of the IV before it is used as a counter. The
+// default is cipher.NewCTR with the counter masking described in RFC 5297.
+//
+// This is an escape hatch for interoperating with nonstandard SIV variants,
+// and ciphertexts produced with any other stream are not RFC 5297 compliant.
+func WithCTR(newCTR func(block cipher.Block, v []byte) cipher.Stream) Option {
+	return func(s *SIV) {
+		s.newCTR = newCTR
+	}
+}
+
+// WithMagic prefixes every ciphertext with magic, so that sealed data can be
+// recognised by its first bytes. The magic is also authenticated as an
+// associated data component preceding all others, so it cannot be stripped or
+// replaced without the ciphertext failing to open, and ciphertexts which do not
+// begin with it are rejected. Overhead includes the length of the magic.
+func WithMagic(magic []byte) Option {
+	return func(s *SIV) {
+		if len(magic) > 0 {
+			s.magic = append([]byte(nil), magic...)
+		}
+	}
+}
+
+// WithNonceSize sets the nonce size reported by NonceSize. If size is
+// positive, every method which seals or opens then requires a nonce of exactly
+// that many bytes, authenticated as the final associated data component, as
+// NewWithNonceSize does. Seal panics on a nonce of any other length, and Open
+// returns an error, as do methods which take no nonce.
+func WithNonceSize(size int) Option {
+	return func(s *SIV) {
+		s.nonceSize = size
+		s.exactNonce = size > 0
+	}
+}
+
+// WithChunkSize sets the number of bytes SealTo encrypts and writes at a time.
+// The default is 64 KiB. It does not change the ciphertext.
+func WithChunkSize(size int) Option {
+	return func(s *SIV) {
+		s.chunkSize = size
+	}
+}
+
+// WithParallelCTR encrypts and decrypts messages of at least minSize bytes
+// with up to workers goroutines, each generating the keystream for its own
+// run of blocks. Smaller messages, and every message when workers is below 2,
+// are handled by the calling goroutine alone, as by default. It does not
+// change the ciphertext, and has no effect with WithCTR, as an arbitrary CTR
+// stream cannot be started partway through.
+func WithParallelCTR(workers, minSize int) Option {
+	return func(s *SIV) {
+		s.ctrWorkers, s.ctrMinSize = workers, minSize
+	}
+}
+
+// WithMaxOpenLen limits the length of the plaintexts Open and its variants
+// will return to n bytes. Longer ciphertexts are rejected before anything is
+// allocated or decrypted, so an attacker cannot force a large allocation with
+// a forged ciphertext. The default, 0, is no limit.
+func WithMaxOpenLen(n int) Option {
+	return func(s *SIV) {
+		s.maxOpenLen = n
+	}
+}
+
+// WithWeakKeyCheck makes New and Reset reject keys which are all zero, as an
+// uninitialized key buffer would be, or whose MAC and encryption halves are
+// equal. It is a heuristic guard against misconfiguration, and is not enabled
+// by default as a properly random or derived key can still fail it, however
+// improbably.
+func WithWeakKeyCheck() Option {
+	return func(s *SIV) {
+		s.weakKeyCheck = true
+	}
+}
+
+// WithStrict enables checks which reject inputs and configurations that are
+// ambiguous or would otherwise be silently accepted, so that two peers cannot
+// interpret the same input differently. In strict mode:
+//
+//   - New returns an error if an option is given a negative size or length,
+//     rather than treating it as the default;
+//   - the nonce given to Seal and Open must be nil if no size is configured
+//     with WithNonceSize, and exactly that size otherwise, so a non-nil empty
+//     nonce is rejected; Seal panics, and Open returns an error;
+//   - CookieSealer rejects values whose base64 encoding is not canonical.
+func WithStrict() Option {
+	return func(s *SIV) {
+		s.strict = true
+	}
+}
+
+func newCTR(block cipher.Block, v []byte) cipher.Stream {
+	return cipher.NewCTR(block, ctr(v))
+}
@@ -0,0 +1,268 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+)
+
+func TestWithCTR(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	// an unmasked counter, as used by some nonstandard variants
+	aead, err := New(key, aes.NewCipher, WithCTR(cipher.NewCTR))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+
+	standard, _ := New(key, aes.NewCipher)
+	if bytes.Equal(ciphertext, standard.Seal(nil, nil, plaintext, data)) {
+		t.Error("Custom CTR produced the standard ciphertext")
+	}
+
+	actual, err := aead.Open(nil, nil, ciphertext, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+}
+
+func TestDefaultCTR(t *testing.T) {
+	// https://tools.ietf.org/html/rfc5297#appendix-A.1
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	ciphertext, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	aead, err := New(key, aes.NewCipher, WithCTR(newCTR))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := aead.Seal(nil, nil, plaintext, data)
+	if !bytes.Equal(actual, ciphertext) {
+		t.Errorf("Ciphertext was %x, but expected %x", actual, ciphertext)
+	}
+}
+
+func TestOverheadWithOptions(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	// every option which could affect the size of a ciphertext
+	options := []Option{
+		WithCTR(newCTR),
+		WithMagic([]byte("SIV1")),
+		WithNonceSize(12),
+		WithChunkSize(16),
+	}
+
+	for set := 0; set < 1<<len(options); set++ {
+		var opts []Option
+		for i, opt := range options {
+			if set&(1<<i) != 0 {
+				opts = append(opts, opt)
+			}
+		}
+
+		aead, err := New(key, aes.NewCipher, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, n := range []int{0, 1, 16, 17, 100} {
+			plaintext := make([]byte, n)
+			ciphertext := aead.Seal(nil, make([]byte, aead.NonceSize()), plaintext, data)
+
+			if v, want := len(ciphertext)-len(plaintext), aead.Overhead(); v != want {
+				t.Errorf("Options %b added %d bytes to %d, but Overhead was %d", set, v, n, want)
+			}
+
+			if v, err := aead.DecryptedLen(len(ciphertext)); err != nil || v != n {
+				t.Errorf("Options %b gave DecryptedLen %d (%v) for %d bytes, but expected %d", set, v, err, len(ciphertext), n)
+			}
+		}
+
+		for _, n := range []int{-1, 0, aead.Overhead() - 1} {
+			if v, err := aead.DecryptedLen(n); err != errCiphertextSize {
+				t.Errorf("Options %b gave DecryptedLen %d (%v) for %d bytes, but expected an error", set, v, err, n)
+			}
+		}
+	}
+}
+
+func TestWithMagic(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	magic := []byte("SIV1")
+
+	aead, err := New(key, aes.NewCipher, WithMagic(magic))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, want := aead.Overhead(), len(magic)+aes.BlockSize; v != want {
+		t.Errorf("Overhead was %d, but expected %d", v, want)
+	}
+
+	for _, n := range []int{0, 14, 100} {
+		plaintext := bytes.Repeat([]byte{byte(n)}, n)
+		ciphertext := aead.Seal(nil, nil, plaintext, data)
+
+		if !bytes.HasPrefix(ciphertext, magic) {
+			t.Errorf("Ciphertext %x did not begin with %x", ciphertext, magic)
+		}
+
+		if !aead.Valid(ciphertext) {
+			t.Errorf("Ciphertext %x was not valid", ciphertext)
+		}
+
+		actual, err := aead.Open(nil, nil, ciphertext, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual, plaintext) {
+			t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+		}
+	}
+}
+
+func TestWithMagicMismatch(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher, WithMagic([]byte("SIV1")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := New(key, aes.NewCipher, WithMagic([]byte("SIV2")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := other.Seal(nil, nil, plaintext, data)
+
+	if aead.Valid(ciphertext) {
+		t.Errorf("Ciphertext %x with the wrong magic was valid", ciphertext)
+	}
+
+	actual, err := aead.Open(nil, nil, ciphertext, data)
+	if err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v (plaintext %x)", err, ErrAuthentication, actual)
+	}
+
+	// swapping in the expected magic still fails, as the magic was authenticated
+	copy(ciphertext, "SIV1")
+
+	actual, err = aead.Open(nil, nil, ciphertext, data)
+	if err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v (plaintext %x)", err, ErrAuthentication, actual)
+	}
+}
+
+func TestWithMaxOpenLen(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, aes.NewCipher, WithMaxOpenLen(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 99, 100} {
+		plaintext := bytes.Repeat([]byte{byte(n)}, n)
+
+		actual, err := aead.Open(nil, nil, aead.Seal(nil, nil, plaintext, data), data)
+		if err != nil {
+			t.Fatalf("Open of %d bytes failed: %v", n, err)
+		}
+
+		if !bytes.Equal(actual, plaintext) {
+			t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+		}
+	}
+
+	for _, n := range []int{101, 1 << 20} {
+		ciphertext := aead.Seal(nil, nil, make([]byte, n), data)
+
+		if actual, err := aead.Open(nil, nil, ciphertext, data); err != errMaxOpenLen {
+			t.Errorf("Error for %d bytes was %v, but expected %v (plaintext %d bytes)", n, err, errMaxOpenLen, len(actual))
+		}
+
+		if actual, err := aead.OpenAt(ciphertext, data, 0); err != errMaxOpenLen {
+			t.Errorf("OpenAt error for %d bytes was %v, but expected %v (plaintext %d bytes)", n, err, errMaxOpenLen, len(actual))
+		}
+	}
+}
+
+func TestWithMaxOpenLenAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items under the race detector")
+	}
+
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	aead, err := New(key, aes.NewCipher, WithMaxOpenLen(1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a forged ciphertext well over the limit
+	ciphertext := make([]byte, 16<<20)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, err := aead.Open(nil, nil, ciphertext, nil); err != errMaxOpenLen {
+			t.Fatalf("Error was %v, but expected %v", err, errMaxOpenLen)
+		}
+	})
+
+	if allocs != 0 {
+		t.Errorf("Open of an over-limit ciphertext allocated %v times, but expected 0", allocs)
+	}
+}
+
+func TestWithWeakKeyCheck(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	half, _ := hex.DecodeString("7f7e7d7c7b7a79787776757473727170")
+
+	for _, v := range []struct {
+		name string
+		key  []byte
+		err  error
+	}{
+		{"normal", key, nil},
+		{"all-zero", make([]byte, 32), errZeroKey},
+		{"all-zero 512-bit", make([]byte, 64), errZeroKey},
+		{"equal halves", append(append([]byte(nil), half...), half...), errEqualHalves},
+	} {
+		if _, err := New(v.key, aes.NewCipher, WithWeakKeyCheck()); err != v.err {
+			t.Errorf("Error for the %s key was %v, but expected %v", v.name, err, v.err)
+		}
+
+		// without the check, every key is accepted
+		if _, err := New(v.key, aes.NewCipher); err != nil {
+			t.Errorf("Error for the %s key without the check was %v", v.name, err)
+		}
+	}
+
+	aead, err := New(key, aes.NewCipher, WithWeakKeyCheck())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := aead.Reset(make([]byte, 32)); err != errZeroKey {
+		t.Errorf("Error resetting to an all-zero key was %v, but expected %v", err, errZeroKey)
+	}
+}
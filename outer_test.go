@@ -0,0 +1,77 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stripe/siv-go/internal/cmac"
+)
+
+func TestOuter(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	meta := []byte("route=eu-west-1")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+	tag := aead.Outer(meta, ciphertext)
+
+	if err := aead.VerifyOuter(meta, ciphertext, tag); err != nil {
+		t.Fatal(err)
+	}
+
+	meta[len(meta)-1] ^= 1
+
+	if err := aead.VerifyOuter(meta, ciphertext, tag); err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v", err, ErrAuthentication)
+	}
+}
+
+func TestOuterDomainSeparation(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	msg := []byte("route=eu-west-1")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := cmac.New(aead.mac, aead.k1, aead.k2)
+	_, _ = h.Write(make([]byte, 8))
+	_, _ = h.Write(msg)
+	plain := h.Sum(nil)
+
+	if outer := aead.Outer(nil, msg); bytes.Equal(outer, plain) {
+		t.Errorf("Outer tag %x was the same as the CMAC of the same input", outer)
+	}
+
+	// the metadata boundary is bound
+	if bytes.Equal(aead.Outer(msg[:5], msg[5:]), aead.Outer(msg[:6], msg[6:])) {
+		t.Error("Outer tags collided for different metadata boundaries")
+	}
+}
+
+func TestOuterAfterReset(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	other := bytes.Repeat([]byte{2}, 32)
+	msg := []byte("route=eu-west-1")
+
+	aead, _ := New(key, aes.NewCipher)
+	fresh, _ := New(other, aes.NewCipher)
+
+	if err := aead.Reset(other); err != nil {
+		t.Fatal(err)
+	}
+
+	// the subkeys for Outer are derived with the others
+	if actual, expected := aead.Outer(nil, msg), fresh.Outer(nil, msg); !bytes.Equal(actual, expected) {
+		t.Errorf("Outer tag after Reset was %x, but expected %x", actual, expected)
+	}
+}
@@ -0,0 +1,48 @@
+package siv
+
+import (
+	"unsafe"
+)
+
+// checkOverlap panics if the n bytes which will be appended to dst overlap the
+// input in other than exactly, any of the associated data components ad, or
+// the nonce. Writing the output would otherwise corrupt inputs that are still
+// being read.
+func checkOverlap(dst []byte, n int, in []byte, ad [][]byte, nonce []byte) {
+	if cap(dst)-len(dst) < n {
+		// the output will be newly allocated
+		return
+	}
+
+	out := dst[len(dst) : len(dst)+n]
+
+	if inexactOverlap(out, in) {
+		panic("siv: invalid buffer overlap")
+	}
+
+	for _, v := range ad {
+		if anyOverlap(out, v) {
+			panic("siv: invalid buffer overlap of associated data")
+		}
+	}
+
+	if anyOverlap(out, nonce) {
+		panic("siv: invalid buffer overlap of associated data")
+	}
+}
+
+// anyOverlap reports whether x and y share memory at any index.
+func anyOverlap(x, y []byte) bool {
+	return len(x) > 0 && len(y) > 0 &&
+		uintptr(unsafe.Pointer(&x[0])) <= uintptr(unsafe.Pointer(&y[len(y)-1])) &&
+		uintptr(unsafe.Pointer(&y[0])) <= uintptr(unsafe.Pointer(&x[len(x)-1]))
+}
+
+// inexactOverlap reports whether x and y share memory at any index other than
+// the same one, allowing in-place operation with dst set to in[:0].
+func inexactOverlap(x, y []byte) bool {
+	if len(x) == 0 || len(y) == 0 || &x[0] == &y[0] {
+		return false
+	}
+	return anyOverlap(x, y)
+}
@@ -0,0 +1,82 @@
+package siv
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSealAliasedData(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext := make([]byte, 64, 128)
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Seal did not panic with associated data aliasing the plaintext")
+		}
+	}()
+
+	// sealing in place overwrites the plaintext, and with it the data
+	aead.Seal(plaintext[:0], nil, plaintext, plaintext[8:16])
+}
+
+func TestOpenAliasedData(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, make([]byte, 64), nil)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Open did not panic with associated data aliasing the ciphertext")
+		}
+	}()
+
+	_, _ = aead.Open(ciphertext[:0], nil, ciphertext, ciphertext[32:48])
+}
+
+func TestSealInexactOverlap(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	buf := make([]byte, 128)
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Seal did not panic with an inexactly overlapping dst")
+		}
+	}()
+
+	aead.Seal(buf[:1], nil, buf[:64], nil)
+}
+
+func TestAnyOverlap(t *testing.T) {
+	buf := make([]byte, 32)
+
+	for _, v := range []struct {
+		x, y    []byte
+		overlap bool
+	}{
+		{buf[:16], buf[16:], false},
+		{buf[:17], buf[16:], true},
+		{buf[4:8], buf[:32], true},
+		{buf[:0], buf, false},
+		{buf, make([]byte, 32), false},
+	} {
+		if actual := anyOverlap(v.x, v.y); actual != v.overlap {
+			t.Errorf("Overlap of %p+%d and %p+%d was %v, but expected %v", v.x, len(v.x), v.y, len(v.y), actual, v.overlap)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package siv
+
+import "sync"
+
+// parallel reports whether a message of n bytes is encrypted by
+// parallelXORKeyStream.
+func (s *SIV) parallel(n int) bool {
+	return s.ctrWorkers > 1 && s.newCTR == nil && n >= s.ctrMinSize && n > s.enc.BlockSize()
+}
+
+// parallelXORKeyStream encrypts or decrypts b in place with the keystream for
+// the synthetic IV v, splitting it into whole-block runs for its workers.
+func (s *SIV) parallelXORKeyStream(b, v []byte) {
+	bs := s.enc.BlockSize()
+	blocks := (len(b) + bs - 1) / bs
+
+	workers := s.ctrWorkers
+	if workers > blocks {
+		workers = blocks
+	}
+	per := (blocks + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for off := 0; off < len(b); off += per * bs {
+		end := off + per*bs
+		if end > len(b) {
+			end = len(b)
+		}
+
+		wg.Add(1)
+		go func(part []byte, block uint64) {
+			defer wg.Done()
+			s.streamAt(v, block).XORKeyStream(part, part)
+		}(b[off:end], uint64(off/bs))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,99 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+func TestParallelCTR(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	serial, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, workers := range []int{2, 3, 8, 1000} {
+		aead, err := New(key, aes.NewCipher, WithParallelCTR(workers, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, n := range []int{0, 1, 16, 17, 100, 1000, 4096, 100003} {
+			plaintext := make([]byte, n)
+			for i := range plaintext {
+				plaintext[i] = byte(i)
+			}
+
+			expected := serial.Seal(nil, nil, plaintext, data)
+			actual := aead.Seal(nil, nil, plaintext, data)
+			if !bytes.Equal(actual, expected) {
+				t.Errorf("Ciphertext of %d bytes with %d workers differed from serial", n, workers)
+			}
+
+			decrypted, err := aead.Open(nil, nil, actual, data)
+			if err != nil {
+				t.Fatalf("Open of %d bytes with %d workers: %v", n, workers, err)
+			}
+
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("Plaintext of %d bytes with %d workers did not round trip", n, workers)
+			}
+		}
+	}
+}
+
+func TestParallelCTRThreshold(t *testing.T) {
+	aead, err := New(make([]byte, 32), aes.NewCipher, WithParallelCTR(4, 1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []struct {
+		n        int
+		parallel bool
+	}{
+		{16, false},
+		{1023, false},
+		{1024, true},
+		{1 << 20, true},
+	} {
+		if p := aead.parallel(v.n); p != v.parallel {
+			t.Errorf("Parallel for %d bytes was %v, but expected %v", v.n, p, v.parallel)
+		}
+	}
+
+	custom, err := New(make([]byte, 32), aes.NewCipher, WithParallelCTR(4, 0), WithCTR(newCTR))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if custom.parallel(1 << 20) {
+		t.Error("Parallel with a custom CTR stream")
+	}
+}
+
+func BenchmarkParallelCTR(b *testing.B) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext := make([]byte, 8<<20)
+	dst := make([]byte, 0, len(plaintext)+aes.BlockSize)
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		aead, err := New(key, aes.NewCipher, WithParallelCTR(workers, 0))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(fmt.Sprint(workers), func(b *testing.B) {
+			b.SetBytes(int64(len(plaintext)))
+
+			for i := 0; i < b.N; i++ {
+				aead.Seal(dst, nil, plaintext, nil)
+			}
+		})
+	}
+}
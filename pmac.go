@@ -0,0 +1,150 @@
+package siv
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"math/bits"
+
+	"github.com/stripe/siv-go/internal/cmac"
+)
+
+// pmacMaxL is the number of offsets precomputed by pmacOffsets, enough for
+// messages of up to 2^64 blocks.
+const pmacMaxL = 64
+
+// pmac is the PMAC1 message authentication code of Black and Rogaway, as used
+// by AES-PMAC-SIV in the Miscreant libraries. Unlike CMAC, each block is
+// encrypted independently of the others. Its offsets are derived once per key
+// by pmacOffsets and shared across instances.
+type pmac struct {
+	b                      cipher.Block
+	l                      [][]byte // L·x^i, for L the encryption of zero
+	linv                   []byte   // L·x^-1
+	digest, buf, offset, t []byte
+	n                      int
+	ctr                    uint64
+}
+
+// pmacOffsets derives the PMAC offsets for the given block cipher.
+func pmacOffsets(b cipher.Block) (l [][]byte, linv []byte) {
+	size := b.BlockSize()
+	buf := make([]byte, (pmacMaxL+1)*size)
+
+	l = make([][]byte, pmacMaxL)
+	for i := range l {
+		l[i] = buf[i*size : (i+1)*size]
+		if i == 0 {
+			b.Encrypt(l[0], l[0])
+		} else {
+			copy(l[i], l[i-1])
+			cmac.Dbl(l[i])
+		}
+	}
+
+	linv = buf[pmacMaxL*size:]
+	copy(linv, l[0])
+	halve(linv)
+
+	return l, linv
+}
+
+func newPMAC(b cipher.Block, l [][]byte, linv []byte) *pmac {
+	return &pmac{
+		b:      b,
+		l:      l,
+		linv:   linv,
+		digest: make([]byte, b.BlockSize()),
+		buf:    make([]byte, b.BlockSize()),
+		offset: make([]byte, b.BlockSize()),
+		t:      make([]byte, b.BlockSize()),
+	}
+}
+
+func (h *pmac) Size() int {
+	return h.b.BlockSize()
+}
+
+func (h *pmac) BlockSize() int {
+	return h.b.BlockSize()
+}
+
+func (h *pmac) Reset() {
+	for i := range h.digest {
+		h.digest[i] = 0
+		h.offset[i] = 0
+	}
+	h.n = 0
+	h.ctr = 0
+}
+
+func (h *pmac) Write(p []byte) (int, error) {
+	n := len(p)
+	size := len(h.buf)
+
+	if h.n < size {
+		c := copy(h.buf[h.n:], p)
+		h.n += c
+		p = p[c:]
+	}
+
+	if len(p) == 0 {
+		return n, nil
+	}
+
+	// As with CMAC, a full buffer is only processed once more input arrives,
+	// as the final block is treated differently.
+	h.block(h.buf)
+
+	for len(p) > size {
+		h.block(p[:size])
+		p = p[size:]
+	}
+
+	h.n = copy(h.buf, p)
+	return n, nil
+}
+
+// block encrypts x under the next offset and adds it to the digest.
+func (h *pmac) block(x []byte) {
+	h.ctr++
+	subtle.XORBytes(h.offset, h.offset, h.l[bits.TrailingZeros64(h.ctr)])
+
+	t := h.t
+	subtle.XORBytes(t, x, h.offset)
+	h.b.Encrypt(t, t)
+	subtle.XORBytes(h.digest, h.digest, t)
+}
+
+func (h *pmac) Sum(b []byte) []byte {
+	x := h.t
+	copy(x, h.digest)
+
+	if h.n == len(h.buf) {
+		subtle.XORBytes(x, x, h.buf)
+		subtle.XORBytes(x, x, h.linv)
+	} else {
+		subtle.XORBytes(x, x[:h.n], h.buf[:h.n])
+		x[h.n] ^= 0x80
+	}
+
+	h.b.Encrypt(x, x)
+	return append(b, x...)
+}
+
+// halve divides b by x in GF(2^n), the inverse of dbl.
+func halve(b []byte) {
+	// as in dbl, a mask rather than a branch keeps this constant time
+	mask := -(b[len(b)-1] & 1)
+	shiftRight(b)
+	b[0] ^= 0x80 & mask
+	b[len(b)-1] ^= (cmac.Rb(len(b)) >> 1) & mask
+}
+
+func shiftRight(b []byte) {
+	overflow := byte(0)
+	for i, v := range b {
+		b[i] >>= 1
+		b[i] |= overflow
+		overflow = (v & 1) << 7
+	}
+}
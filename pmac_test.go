@@ -0,0 +1,138 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stripe/siv-go/internal/cmac"
+)
+
+func TestPMAC(t *testing.T) {
+	// the AES-128 test vectors published with PMAC1
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	block, _ := aes.NewCipher(key)
+	l, linv := pmacOffsets(block)
+
+	message := make([]byte, 34)
+	for i := range message {
+		message[i] = byte(i)
+	}
+
+	for _, v := range []struct {
+		n   int
+		tag string
+	}{
+		{0, "4399572cd6ea5341b8d35876a7098af7"},
+		{3, "256ba5193c1b991b4df0c51f388a9e27"},
+		{16, "ebbd822fa458daf6dfdad7c27da76338"},
+		{20, "0412ca150bbf79058d8c75a58c993f55"},
+		{32, "e97ac04e9e5e3399ce5355cd7407bc75"},
+		{34, "5cba7d5eb24f7c86ccc54604e53d5512"},
+	} {
+		expected, _ := hex.DecodeString(v.tag)
+
+		h := newPMAC(block, l, linv)
+		_, _ = h.Write(message[:v.n])
+
+		if actual := h.Sum(nil); !bytes.Equal(actual, expected) {
+			t.Errorf("PMAC of %d bytes was %x, but expected %x", v.n, actual, expected)
+		}
+	}
+
+	expected, _ := hex.DecodeString("c2c9fa1d9985f6f0d2aff915a0e8d910")
+	h := newPMAC(block, l, linv)
+	_, _ = h.Write(make([]byte, 1000))
+
+	if actual := h.Sum(nil); !bytes.Equal(actual, expected) {
+		t.Errorf("PMAC of 1000 zero bytes was %x, but expected %x", actual, expected)
+	}
+}
+
+func TestHalve(t *testing.T) {
+	for _, size := range []int{8, 16} {
+		for _, low := range []byte{0, 1} {
+			b := make([]byte, size)
+			for i := range b {
+				b[i] = byte(0x82 * (i + 1))
+			}
+			b[size-1] |= low
+			expected := append([]byte(nil), b...)
+
+			halve(b)
+			cmac.Dbl(b)
+
+			if !bytes.Equal(b, expected) {
+				t.Errorf("dbl(halve(x)) was %x, but expected %x", b, expected)
+			}
+		}
+	}
+}
+
+func TestPMACSIV(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := NewPMACSIV(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 1, 16, 17, 1000} {
+		plaintext := bytes.Repeat([]byte{0xa5}, n)
+		ciphertext := aead.Seal(nil, nil, plaintext, data)
+
+		actual, err := aead.Open(nil, nil, ciphertext, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual, plaintext) {
+			t.Errorf("Plaintext of %d bytes did not round trip", n)
+		}
+
+		if err := aead.VerifyStream(bytes.NewReader(ciphertext), data); err != nil {
+			t.Errorf("VerifyStream of %d bytes failed: %v", n, err)
+		}
+
+		if _, err := plain.Open(nil, nil, ciphertext, data); err != ErrAuthentication {
+			t.Errorf("CMAC-SIV opened a PMAC-SIV ciphertext of %d bytes", n)
+		}
+	}
+}
+
+func TestPMACWriteSplits(t *testing.T) {
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	block, _ := aes.NewCipher(key)
+	l, linv := pmacOffsets(block)
+
+	msg := make([]byte, 70)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+
+	for n := 0; n <= len(msg); n++ {
+		h := newPMAC(block, l, linv)
+		_, _ = h.Write(msg[:n])
+		expected := h.Sum(nil)
+
+		for i := 0; i <= n; i++ {
+			for j := i; j <= n; j++ {
+				h.Reset()
+				_, _ = h.Write(msg[:i])
+				_, _ = h.Write(msg[i:j])
+				_, _ = h.Write(msg[j:n])
+
+				if actual := h.Sum(nil); !bytes.Equal(actual, expected) {
+					t.Fatalf("PMAC of %d bytes written at %d and %d was %x, but expected %x", n, i, j, actual, expected)
+				}
+			}
+		}
+	}
+}
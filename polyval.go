@@ -0,0 +1,100 @@
+package siv
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// polyval is the POLYVAL universal hash described in RFC 8452, over
+// GF(2^128) with the polynomial x^128 + x^127 + x^126 + x^121 + 1. Elements
+// are little-endian: bit i of lo is the coefficient of x^i, and bit i of hi
+// that of x^(64+i).
+type polyval struct {
+	h0, h1 uint64 // the key H
+	s0, s1 uint64 // the accumulator S
+}
+
+func newPolyval(h []byte) *polyval {
+	return &polyval{
+		h0: binary.LittleEndian.Uint64(h[0:8]),
+		h1: binary.LittleEndian.Uint64(h[8:16]),
+	}
+}
+
+// update absorbs b, zero-padding it to a multiple of the block size.
+func (p *polyval) update(b []byte) {
+	for len(b) >= 16 {
+		p.block(b)
+		b = b[16:]
+	}
+
+	if len(b) > 0 {
+		var last [16]byte
+		copy(last[:], b)
+		p.block(last[:])
+	}
+}
+
+// block absorbs a single block: S = dot(S + X, H).
+func (p *polyval) block(x []byte) {
+	p.s0 ^= binary.LittleEndian.Uint64(x[0:8])
+	p.s1 ^= binary.LittleEndian.Uint64(x[8:16])
+	p.s0, p.s1 = dot(p.s0, p.s1, p.h0, p.h1)
+}
+
+// sum appends the current value of S to b.
+func (p *polyval) sum(b []byte) []byte {
+	b = binary.LittleEndian.AppendUint64(b, p.s0)
+	return binary.LittleEndian.AppendUint64(b, p.s1)
+}
+
+// dot returns a * b * x^-128, reduced.
+func dot(a0, a1, b0, b1 uint64) (uint64, uint64) {
+	// the 256-bit carry-less product
+	z0, z1 := clmul(a0, b0)
+	z2, z3 := clmul(a1, b1)
+	m0, m1 := clmul(a0, b1)
+	n0, n1 := clmul(a1, b0)
+	z1 ^= m0 ^ n0
+	z2 ^= m1 ^ n1
+
+	// Montgomery reduction, a word at a time: as the polynomial is 1 modulo
+	// x^64, adding the low word times the polynomial clears it, leaving
+	// the product divisible by x^64
+	z2 ^= z0 ^ z0>>1 ^ z0>>2 ^ z0>>7
+	z1 ^= z0<<63 ^ z0<<62 ^ z0<<57
+
+	z3 ^= z1 ^ z1>>1 ^ z1>>2 ^ z1>>7
+	z2 ^= z1<<63 ^ z1<<62 ^ z1<<57
+
+	return z2, z3
+}
+
+// clmul returns the 128-bit carry-less product of x and y, in constant time.
+func clmul(x, y uint64) (lo, hi uint64) {
+	lo = bmul64(x, y)
+	hi = bits.Reverse64(bmul64(bits.Reverse64(x), bits.Reverse64(y))) >> 1
+	return lo, hi
+}
+
+// bmul64 returns the low 64 bits of the carry-less product of x and y, using
+// integer multiplication with every fourth bit masked out so that carries
+// cannot reach the bits which are kept.
+func bmul64(x, y uint64) uint64 {
+	const (
+		m0 = 0x1111111111111111
+		m1 = 0x2222222222222222
+		m2 = 0x4444444444444444
+		m3 = 0x8888888888888888
+	)
+
+	x0, x1, x2, x3 := x&m0, x&m1, x&m2, x&m3
+	y0, y1, y2, y3 := y&m0, y&m1, y&m2, y&m3
+
+	z0 := x0*y0 ^ x1*y3 ^ x2*y2 ^ x3*y1
+	z1 := x0*y1 ^ x1*y0 ^ x2*y3 ^ x3*y2
+	z2 := x0*y2 ^ x1*y1 ^ x2*y0 ^ x3*y3
+	z3 := x0*y3 ^ x1*y2 ^ x2*y1 ^ x3*y0
+
+	return z0&m0 | z1&m1 | z2&m2 | z3&m3
+}
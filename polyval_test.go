@@ -0,0 +1,22 @@
+package siv
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestPolyval(t *testing.T) {
+	// https://tools.ietf.org/html/rfc8452#appendix-A
+	h, _ := hex.DecodeString("25629347589242761d31f826ba4b757b")
+	x1, _ := hex.DecodeString("4f4f95668c83dfb6401762bb2d01a262")
+	x2, _ := hex.DecodeString("d1a24ddd2721d006bbe45f20d3c9f362")
+	expected, _ := hex.DecodeString("f7a3b47b846119fae5b7866cf5e5b77e")
+
+	p := newPolyval(h)
+	p.update(append(x1, x2...))
+
+	if actual := p.sum(nil); !bytes.Equal(actual, expected) {
+		t.Errorf("POLYVAL was %x, but expected %x", actual, expected)
+	}
+}
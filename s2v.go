@@ -0,0 +1,190 @@
+package siv
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"hash"
+	"io"
+
+	"github.com/stripe/siv-go/internal/cmac"
+)
+
+// S2V computes the S2V function of RFC 5297 with the block cipher b, keyed
+// with the MAC half of an SIV key, over the given inputs: the last is treated
+// as the plaintext, and the others as associated data. It is a PRF over a
+// vector of strings, binding each one unambiguously, with nil inputs treated
+// as empty. With no inputs the result is the CMAC of <one>, as the RFC
+// specifies. It returns an error if b's block size is not 16 bytes.
+func S2V(b cipher.Block, inputs ...[]byte) ([16]byte, error) {
+	var v [16]byte
+	if b.BlockSize() != len(v) {
+		return v, errBlockSize
+	}
+
+	k1, k2 := cmac.Subkeys(b)
+	h := cmac.New(b, k1, k2)
+
+	if len(inputs) == 0 {
+		v[len(v)-1] = 1
+		_, _ = h.Write(v[:])
+		h.Sum(v[:0])
+		return v, nil
+	}
+
+	copy(v[:], s2v(h, components(inputs)...))
+	return v, nil
+}
+
+// s2v computes the S2V function of RFC 5297 over the given components, the
+// last of which is the plaintext. Each component is MACed on its own before
+// being folded into the result, so components are bound unambiguously: no
+// two different partitions of the same bytes produce the same output.
+func s2v(h hash.Hash, data ...[]byte) []byte {
+	return s2vAD(h, data[:len(data)-1], data[len(data)-1])
+}
+
+// s2vAD computes S2V over the associated data components ad, skipping any that
+// are nil, followed by the plaintext.
+func s2vAD(h hash.Hash, ad [][]byte, plaintext []byte) []byte {
+	st := newS2V(h)
+
+	for _, v := range ad {
+		if v == nil {
+			continue
+		}
+
+		st.add(v)
+	}
+
+	return st.sum(plaintext)
+}
+
+// s2vState is the running state of S2V, to which associated data components
+// are added one at a time.
+type s2vState struct {
+	h    hash.Hash
+	d, t []byte
+}
+
+func newS2V(h hash.Hash) s2vState {
+	return initS2V(h, make([]byte, h.BlockSize()), make([]byte, h.BlockSize()))
+}
+
+// initS2V starts S2V using d and t, which must be a block long, for its state
+// and as scratch space.
+func initS2V(h hash.Hash, d, t []byte) s2vState {
+	for i := range d {
+		d[i] = 0
+	}
+
+	h.Reset()
+	_, _ = h.Write(d)
+	d = h.Sum(d[:0])
+	h.Reset()
+
+	return s2vState{h: h, d: d, t: t}
+}
+
+// add adds an associated data component.
+func (st *s2vState) add(v []byte) {
+	cmac.Dbl(st.d)
+
+	_, _ = st.h.Write(v)
+
+	st.fold()
+}
+
+// readFrom adds an associated data component read from r until EOF.
+func (st *s2vState) readFrom(r io.Reader) error {
+	cmac.Dbl(st.d)
+
+	if _, err := io.Copy(st.h, r); err != nil {
+		st.h.Reset()
+		return err
+	}
+
+	st.fold()
+	return nil
+}
+
+func (st *s2vState) fold() {
+	subtle.XORBytes(st.d, st.d, st.h.Sum(st.t[:0]))
+
+	st.h.Reset()
+}
+
+// sum adds the final component and returns the result.
+func (st *s2vState) sum(v []byte) []byte {
+	h, d := st.h, st.d
+
+	if len(v) >= h.BlockSize() {
+		// xorend
+		prefix := len(v) - len(d)
+		_, _ = h.Write(v[:prefix])
+		subtle.XORBytes(d, d, v[prefix:])
+		_, _ = h.Write(d)
+	} else {
+		cmac.Dbl(d)
+
+		// pad and xor
+		subtle.XORBytes(d, d[:len(v)], v)
+		d[len(v)] ^= 0x80
+
+		_, _ = h.Write(d)
+	}
+
+	return h.Sum(d[:0])
+}
+
+// s2vWriter computes the final S2V component from a sequence of writes. It
+// holds back the most recent block written, as the last block must be
+// combined with the running state once the end of the component is known.
+type s2vWriter struct {
+	st   s2vState
+	tail []byte
+	n    int
+}
+
+func newS2VWriter(st s2vState) *s2vWriter {
+	return &s2vWriter{
+		st:   st,
+		tail: make([]byte, st.h.BlockSize()),
+	}
+}
+
+func (w *s2vWriter) Write(p []byte) (int, error) {
+	n := len(p)
+
+	if excess := w.n + len(p) - len(w.tail); excess > 0 {
+		held := excess
+		if held > w.n {
+			held = w.n
+		}
+
+		_, _ = w.st.h.Write(w.tail[:held])
+		w.n = copy(w.tail, w.tail[held:w.n])
+
+		_, _ = w.st.h.Write(p[:excess-held])
+		p = p[excess-held:]
+	}
+
+	w.n += copy(w.tail[w.n:], p)
+
+	return n, nil
+}
+
+// Sum returns the result of S2V with everything written as the final
+// component.
+func (w *s2vWriter) Sum() []byte {
+	if w.n < len(w.tail) {
+		return w.st.sum(w.tail[:w.n])
+	}
+
+	// everything but the final block has already been written to the MAC,
+	// which leaves only the xorend of the final block
+	h, d := w.st.h, w.st.d
+	subtle.XORBytes(d, d, w.tail)
+	_, _ = h.Write(d)
+
+	return h.Sum(d[:0])
+}
@@ -2,10 +2,15 @@ package siv
 
 import (
 	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
 	"encoding/hex"
+	"hash"
+	"io"
 	"testing"
 
-	"github.com/ebfe/cmac"
+	"github.com/stripe/siv-go/internal/cmac"
 )
 
 func TestS2V(t *testing.T) {
@@ -18,10 +23,276 @@ func TestS2V(t *testing.T) {
 	plaintext, _ := hex.DecodeString("7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553")
 	expected, _ := hex.DecodeString("7bdb6e3b432667eb06f4d14bff2fbd0f") // CMAC(final)
 
-	h, _ := cmac.New(key)
+	block, _ := aes.NewCipher(key)
+	k1, k2 := cmac.Subkeys(block)
+	h := cmac.New(block, k1, k2)
 	actual := s2v(h, ad1, ad2, nonce, plaintext)
 
 	if !bytes.Equal(actual, expected) {
 		t.Errorf("S2V was %x, but expected %x", actual, expected)
 	}
 }
+
+func TestS2VPartitions(t *testing.T) {
+	key, _ := hex.DecodeString("7f7e7d7c7b7a79787776757473727170")
+	plaintext, _ := hex.DecodeString("7468697320697320736f6d6520706c61696e74657874")
+
+	block, _ := aes.NewCipher(key)
+	k1, k2 := cmac.Subkeys(block)
+
+	a := s2v(cmac.New(block, k1, k2), []byte("ab"), []byte("c"), plaintext)
+	b := s2v(cmac.New(block, k1, k2), []byte("a"), []byte("bc"), plaintext)
+	c := s2v(cmac.New(block, k1, k2), []byte("abc"), plaintext)
+
+	if bytes.Equal(a, b) || bytes.Equal(a, c) || bytes.Equal(b, c) {
+		t.Errorf("Partitions of the same associated data collided: %x, %x, %x", a, b, c)
+	}
+}
+
+func TestS2VReader(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	expected, _ := hex.DecodeString("b175cc589a234a994c55bb3fd7147cd2")
+
+	data := make([]byte, 10*1024*1024)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+
+	block, _ := aes.NewCipher(key)
+	k1, k2 := cmac.Subkeys(block)
+
+	buffered := s2v(cmac.New(block, k1, k2), data, plaintext)
+	if !bytes.Equal(buffered, expected) {
+		t.Errorf("S2V was %x, but expected %x", buffered, expected)
+	}
+
+	st := newS2V(cmac.New(block, k1, k2))
+	if err := st.readFrom(&chunkReader{r: bytes.NewReader(data), n: 4093}); err != nil {
+		t.Fatal(err)
+	}
+
+	if streamed := st.sum(plaintext); !bytes.Equal(streamed, expected) {
+		t.Errorf("Streamed S2V was %x, but expected %x", streamed, expected)
+	}
+}
+
+// chunkReader reads from r at most n bytes at a time.
+type chunkReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	if len(p) > c.n {
+		p = p[:c.n]
+	}
+	return c.r.Read(p)
+}
+
+func TestS2VWriter(t *testing.T) {
+	key, _ := hex.DecodeString("7f7e7d7c7b7a79787776757473727170")
+	ad, _ := hex.DecodeString("102030405060708090a0")
+
+	block, _ := aes.NewCipher(key)
+	k1, k2 := cmac.Subkeys(block)
+
+	for n := 0; n <= 50; n++ {
+		plaintext := bytes.Repeat([]byte{byte(n)}, n)
+		expected := s2v(cmac.New(block, k1, k2), ad, plaintext)
+
+		for _, chunk := range []int{1, 3, 16, 17, 64} {
+			st := newS2V(cmac.New(block, k1, k2))
+			st.add(ad)
+			w := newS2VWriter(st)
+
+			_, _ = io.Copy(w, &chunkReader{r: bytes.NewReader(plaintext), n: chunk})
+
+			if actual := w.Sum(); !bytes.Equal(actual, expected) {
+				t.Errorf("S2V of %d bytes in chunks of %d was %x, but expected %x", n, chunk, actual, expected)
+			}
+		}
+	}
+}
+
+// blockSizes are the block sizes supported by this package, with a cipher for
+// each. RFC 5297 specifies only 16-byte blocks.
+var blockSizes = []struct {
+	size int
+	key  string
+	alg  func([]byte) (cipher.Block, error)
+}{
+	{8, "000102030405060708090a0b0c0d0e0f1011121314151617", des.NewTripleDESCipher},
+	{16, "7f7e7d7c7b7a79787776757473727170", aes.NewCipher},
+}
+
+func TestS2VBlockSizes(t *testing.T) {
+	for _, bs := range blockSizes {
+		key, _ := hex.DecodeString(bs.key)
+		block, _ := bs.alg(key)
+		k1, k2 := cmac.Subkeys(block)
+
+		mac := func(b []byte) []byte {
+			h := cmac.New(block, k1, k2)
+			_, _ = h.Write(b)
+			return h.Sum(nil)
+		}
+
+		ad := []byte("associated data")
+
+		for n := 0; n <= 3*bs.size; n++ {
+			plaintext := bytes.Repeat([]byte{byte(n)}, n)
+
+			d := mac(make([]byte, bs.size))
+			cmac.Dbl(d)
+			for i, v := range mac(ad) {
+				d[i] ^= v
+			}
+
+			var final []byte
+			if n >= bs.size {
+				// xorend
+				final = append([]byte(nil), plaintext...)
+				for i, v := range d {
+					final[n-bs.size+i] ^= v
+				}
+			} else {
+				// dbl and pad
+				cmac.Dbl(d)
+				final = append(append([]byte(nil), plaintext...), 0x80)
+				final = append(final, make([]byte, bs.size-len(final))...)
+				for i, v := range d {
+					final[i] ^= v
+				}
+			}
+			expected := mac(final)
+
+			if actual := s2v(cmac.New(block, k1, k2), ad, plaintext); !bytes.Equal(actual, expected) {
+				t.Errorf("S2V of %d bytes with %d-byte blocks was %x, but expected %x", n, bs.size, actual, expected)
+			}
+		}
+	}
+}
+
+func TestS2VPadFinalByte(t *testing.T) {
+	// a 15-byte plaintext is padded with 0x80 in the last byte of the block;
+	// the ciphertext was computed with OpenSSL's AES-SIV
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddeeff")
+	expected, _ := hex.DecodeString("2bbbc646bd400564c7f4ed4e2105a8ef08037e663eb3365d969c01853e763a")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := aead.Seal(nil, nil, plaintext, data); !bytes.Equal(actual, expected) {
+		t.Errorf("Ciphertext was %x, but expected %x", actual, expected)
+	}
+
+	block, _ := aes.NewCipher(key[:16])
+	k1, k2 := cmac.Subkeys(block)
+
+	mac := func(b []byte) []byte {
+		h := cmac.New(block, k1, k2)
+		_, _ = h.Write(b)
+		return h.Sum(nil)
+	}
+
+	// D as it stands before the final component: dbl(dbl(CMAC(0)) ^ CMAC(data))
+	d := mac(make([]byte, 16))
+	cmac.Dbl(d)
+	for i, v := range mac(data) {
+		d[i] ^= v
+	}
+	cmac.Dbl(d)
+
+	h := &recordingHash{Hash: cmac.New(block, k1, k2)}
+	st := newS2V(h)
+	st.add(data)
+	if v := st.sum(plaintext); !bytes.Equal(v, expected[:16]) {
+		t.Errorf("S2V was %x, but expected %x", v, expected[:16])
+	}
+
+	padded := h.written
+	for i, v := range d {
+		padded[i] ^= v
+	}
+
+	if !bytes.Equal(padded[:15], plaintext) || padded[15] != 0x80 {
+		t.Errorf("Padded block was %x, but expected %x80", padded, plaintext)
+	}
+}
+
+// recordingHash records what was written to it since it was last reset.
+type recordingHash struct {
+	hash.Hash
+	written []byte
+}
+
+func (h *recordingHash) Write(p []byte) (int, error) {
+	h.written = append(h.written, p...)
+	return h.Hash.Write(p)
+}
+
+func (h *recordingHash) Reset() {
+	h.written = h.written[:0]
+	h.Hash.Reset()
+}
+
+func TestExportedS2V(t *testing.T) {
+	// https://tools.ietf.org/html/rfc5297#appendix-A
+	for _, v := range []struct {
+		key    string
+		inputs []string
+		v      string
+	}{
+		{
+			key:    "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0",
+			inputs: []string{"101112131415161718191a1b1c1d1e1f2021222324252627", "112233445566778899aabbccddee"},
+			v:      "85632d07c6e8f37f950acd320a2ecc93",
+		},
+		{
+			key: "7f7e7d7c7b7a79787776757473727170",
+			inputs: []string{
+				"00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100",
+				"102030405060708090a0",
+				"09f911029d74e35bd84156c5635688c0",
+				"7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553",
+			},
+			v: "7bdb6e3b432667eb06f4d14bff2fbd0f",
+		},
+		{
+			// the CMAC of <one>, computed with OpenSSL
+			key: "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0",
+			v:   "949f99cbcc3eb5da6d3c45d0f59aa9c7",
+		},
+	} {
+		key, _ := hex.DecodeString(v.key)
+		expected, _ := hex.DecodeString(v.v)
+
+		inputs := make([][]byte, len(v.inputs))
+		for i, s := range v.inputs {
+			inputs[i], _ = hex.DecodeString(s)
+		}
+
+		block, _ := aes.NewCipher(key)
+		actual, err := S2V(block, inputs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual[:], expected) {
+			t.Errorf("S2V of %d inputs was %x, but expected %x", len(inputs), actual, expected)
+		}
+	}
+}
+
+func TestExportedS2VBlockSize(t *testing.T) {
+	block, _ := des.NewTripleDESCipher(make([]byte, 24))
+
+	if _, err := S2V(block, []byte("x")); err != errBlockSize {
+		t.Errorf("Error was %v, but expected %v", err, errBlockSize)
+	}
+}
@@ -0,0 +1,141 @@
+package siv
+
+import (
+	"crypto/subtle"
+	"hash"
+
+	"github.com/stripe/siv-go/internal/cmac"
+)
+
+// scratch holds the buffers used by a single Seal or Open. They are pooled by
+// each AEAD so that operations do not allocate.
+type scratch struct {
+	mac   hash.Hash // h or p, as set by bind
+	h     cmac.CMAC
+	p     pmac
+	d, t  []byte // S2V state
+	v, ks []byte // synthetic IV and a batch of keystream
+	ctr   []byte // counter for ks
+}
+
+// scratchSize returns the number of bytes allocated by newScratch.
+func scratchSize(size int) int {
+	return (7 + ctrBatchBlocks) * size
+}
+
+func newScratch(size int) *scratch {
+	buf := make([]byte, scratchSize(size))
+	sc := &scratch{
+		// Only one of the MACs is ever bound, so they share buffers. The
+		// PMAC offset also shares ctr, which is never in use during S2V.
+		p: pmac{
+			digest: buf[0*size : 1*size],
+			buf:    buf[1*size : 2*size],
+			t:      buf[2*size : 3*size],
+			offset: buf[6*size : 7*size],
+		},
+		d:   buf[3*size : 4*size],
+		t:   buf[4*size : 5*size],
+		v:   buf[5*size : 6*size],
+		ctr: buf[6*size : 7*size],
+		ks:  buf[7*size:],
+	}
+	sc.h.SetScratch(buf[0*size : 3*size])
+	return sc
+}
+
+func (s *SIV) getScratch() *scratch {
+	sc, _ := s.pool.Get().(*scratch)
+	if sc == nil {
+		sc = newScratch(s.mac.BlockSize())
+	}
+	return sc
+}
+
+func (s *SIV) putScratch(sc *scratch) {
+	s.pool.Put(sc)
+}
+
+// wipe zeroes the scratch space and unbinds its MAC.
+func (sc *scratch) wipe() {
+	sc.h.Wipe()
+	for _, v := range [][]byte{sc.d, sc.t, sc.v, sc.ks, sc.ctr} {
+		for i := range v {
+			v[i] = 0
+		}
+	}
+
+	sc.p.b, sc.p.l, sc.p.linv = nil, nil, nil
+	sc.mac = nil
+}
+
+// bind points the scratch space's MAC at the AEAD's current key.
+func (s *SIV) bind(sc *scratch) {
+	if s.pmac {
+		sc.p.b, sc.p.l, sc.p.linv = s.mac, s.l, s.linv
+		sc.mac = &sc.p
+		return
+	}
+
+	sc.h.SetKey(s.mac, s.k1, s.k2)
+	sc.mac = &sc.h
+}
+
+// s2v computes S2V over the magic and the associated data components ad,
+// skipping any that are nil, then nonce unless it is nil, followed by the
+// plaintext. The result is only valid until sc is reused.
+func (sc *scratch) s2v(magic []byte, ad [][]byte, nonce, plaintext []byte) []byte {
+	st := initS2V(sc.mac, sc.d, sc.t)
+
+	if magic != nil {
+		st.add(magic)
+	}
+
+	for _, v := range ad {
+		if v == nil {
+			continue
+		}
+
+		st.add(v)
+	}
+
+	if nonce != nil {
+		st.add(nonce)
+	}
+
+	return st.sum(plaintext)
+}
+
+// ctrBatchBlocks is the number of blocks of keystream xorKeyStream generates
+// into the scratch space at a time.
+const ctrBatchBlocks = 8
+
+// xorKeyStream encrypts or decrypts b in place with the keystream for the
+// synthetic IV v. The keystream is generated in batches into the scratch
+// space, so no CTR stream is allocated unless one was configured.
+func (s *SIV) xorKeyStream(sc *scratch, b, v []byte) {
+	if s.parallel(len(b)) {
+		s.parallelXORKeyStream(b, v)
+		return
+	}
+
+	if s.newCTR != nil {
+		s.stream(v).XORKeyStream(b, b)
+		return
+	}
+
+	q := sc.ctr
+	copy(q, v)
+	maskCTR(q)
+
+	size := len(q)
+	for len(b) > 0 {
+		ks := sc.ks[:min(len(sc.ks), (len(b)+size-1)/size*size)]
+		for i := 0; i < len(ks); i += size {
+			s.enc.Encrypt(ks[i:i+size], q)
+			addCounter(q, 1)
+		}
+
+		b = b[subtle.XORBytes(b, b, ks):]
+	}
+}
@@ -0,0 +1,40 @@
+package siv
+
+// A Sealer seals and opens messages with the key of the AEAD it was created
+// from, using its own scratch space rather than the AEAD's shared pool. It is
+// not safe for concurrent use, and is intended to be created once per
+// goroutine. It implements cipher.AEAD.
+type Sealer struct {
+	s  *SIV
+	sc *scratch
+}
+
+// NewSealer returns a new Sealer for the AEAD's key. The Sealer follows any
+// later Reset or Close of the AEAD.
+func (s *SIV) NewSealer() *Sealer {
+	return &Sealer{
+		s:  s,
+		sc: newScratch(s.mac.BlockSize()),
+	}
+}
+
+func (z *Sealer) NonceSize() int {
+	return z.s.NonceSize()
+}
+
+func (z *Sealer) Overhead() int {
+	return z.s.Overhead()
+}
+
+func (z *Sealer) Seal(dst, nonce, plaintext, data []byte) []byte {
+	ret, err := z.s.sealWith(z.sc, dst, plaintext, nonce, [][]byte{data})
+	if err != nil {
+		panic("siv: " + err.Error())
+	}
+
+	return ret
+}
+
+func (z *Sealer) Open(dst, nonce, ciphertext, data []byte) ([]byte, error) {
+	return z.s.openWith(z.sc, dst, ciphertext, nonce, [][]byte{data})
+}
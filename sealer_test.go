@@ -0,0 +1,125 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"sync"
+	"testing"
+)
+
+func TestSealer(t *testing.T) {
+	// https://tools.ietf.org/html/rfc5297#appendix-A.1
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	ciphertext, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	z := aead.NewSealer()
+
+	actual := z.Seal(nil, nil, plaintext, data)
+	if !bytes.Equal(actual, ciphertext) {
+		t.Errorf("Ciphertext was %x, but expected %x", actual, ciphertext)
+	}
+
+	actual, err = z.Open(nil, nil, ciphertext, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+
+	ciphertext[0] ^= 1
+
+	actual, err = z.Open(nil, nil, ciphertext, data)
+	if err == nil {
+		t.Fatalf("Plaintext returned instead of error: %x", actual)
+	}
+}
+
+func TestSealerConcurrent(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			z := aead.NewSealer()
+			for j := 0; j < 100; j++ {
+				plaintext := bytes.Repeat([]byte{byte(i)}, j)
+
+				actual, err := z.Open(nil, nil, z.Seal(nil, nil, plaintext, data), data)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				if !bytes.Equal(actual, plaintext) {
+					t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkSealShared(b *testing.B) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.SetBytes(16)
+
+	b.RunParallel(func(pb *testing.PB) {
+		plaintext := make([]byte, 16)
+		dst := make([]byte, 0, 32)
+		for pb.Next() {
+			aead.Seal(dst, nil, plaintext, data)
+		}
+	})
+}
+
+func BenchmarkSealSealer(b *testing.B) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.SetBytes(16)
+
+	b.RunParallel(func(pb *testing.PB) {
+		z := aead.NewSealer()
+		plaintext := make([]byte, 16)
+		dst := make([]byte, 0, 32)
+		for pb.Next() {
+			z.Seal(dst, nil, plaintext, data)
+		}
+	})
+}
@@ -4,147 +4,629 @@
 package siv
 
 import (
+	"bytes"
 	"crypto/cipher"
 	"crypto/subtle"
 	"errors"
 	"hash"
+	"io"
+	"sync"
+	"sync/atomic"
 
-	"github.com/ebfe/cmac"
+	"github.com/stripe/siv-go/internal/cmac"
 )
 
 // New returns a new SIV AEAD with the given key and encryption algorithm. The
 // key must be twice the key size of the underlying algorithm.
-func New(key []byte, alg func([]byte) (cipher.Block, error)) (cipher.AEAD, error) {
-	mac, err := alg(key[:(len(key) / 2)])
-	if err != nil {
+//
+// The block cipher must have a block size of 8 or 16 bytes; others are
+// rejected. RFC 5297 only specifies 16-byte blocks, and for 8-byte block
+// ciphers the same construction is used with the GF(2^64) polynomial of
+// SP 800-38B and bits 31 and 63 of the counter masked.
+//
+// For AES, the key size alone selects the variant: a 32-byte key gives
+// AES-128-SIV and a 64-byte key gives AES-256-SIV. The two are not
+// interoperable, and a ciphertext sealed with one will fail to open with the
+// other. A key of another length may still be accepted: a 48-byte key gives
+// AES-SIV-192. Use NewAES128 or NewAES256 to insist on a particular variant.
+func New(key []byte, alg func([]byte) (cipher.Block, error), opts ...Option) (*SIV, error) {
+	return newSIV(key, alg, false, opts)
+}
+
+// NewPMACSIV is like New, but returns a PMAC-SIV AEAD, which computes S2V with
+// PMAC rather than CMAC, as the AES-PMAC-SIV mode of the Miscreant libraries
+// does. PMAC encrypts each block independently, so unlike CMAC its block
+// cipher calls need not wait on one another. The two modes are not
+// interoperable.
+func NewPMACSIV(key []byte, alg func([]byte) (cipher.Block, error), opts ...Option) (*SIV, error) {
+	return newSIV(key, alg, true, opts)
+}
+
+func newSIV(key []byte, alg func([]byte) (cipher.Block, error), pmac bool, opts []Option) (*SIV, error) {
+	if alg == nil {
+		return nil, errNilAlg
+	}
+
+	s := &SIV{
+		alg:  alg,
+		pmac: pmac,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := s.checkConfig(); err != nil {
 		return nil, err
 	}
 
-	enc, err := alg(key[(len(key) / 2):])
-	if err != nil {
+	if err := s.Reset(key); err != nil {
 		return nil, err
 	}
 
-	return &siv{
-		enc: enc,
-		mac: mac,
-	}, nil
+	return s, nil
+}
+
+// NewWithCiphers is like New, but takes ready-made block ciphers for the MAC
+// and encryption halves of the key, for keys which are not available as
+// bytes, such as those held in an HSM. As there is no key, Reset returns an
+// error and WithWeakKeyCheck has no effect. Close forgets the ciphers but
+// cannot erase any key material they hold.
+func NewWithCiphers(macBlock, encBlock cipher.Block, opts ...Option) (*SIV, error) {
+	if macBlock == nil || encBlock == nil {
+		return nil, errNilBlock
+	}
+
+	s := &SIV{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := s.checkConfig(); err != nil {
+		return nil, err
+	}
+
+	if err := s.setCiphers(macBlock, encBlock); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// NewFromReader is like New, but reads a key of keyLen bytes from r. The
+// temporary copy of the key is zeroed before it returns. If r holds fewer than
+// keyLen bytes, io.ErrUnexpectedEOF (or io.EOF if it was empty) is returned.
+func NewFromReader(r io.Reader, keyLen int, alg func([]byte) (cipher.Block, error), opts ...Option) (*SIV, error) {
+	key := make([]byte, keyLen)
+	defer func() {
+		for i := range key {
+			key[i] = 0
+		}
+	}()
+
+	if _, err := io.ReadFull(r, key); err != nil {
+		return nil, err
+	}
+
+	return New(key, alg, opts...)
+}
+
+// SplitKey splits an SIV key into the halves used for the MAC and for
+// encryption, which alias key. It returns an error if key cannot be split
+// evenly.
+func SplitKey(key []byte) (macKey, encKey []byte, err error) {
+	if len(key) == 0 || len(key)%2 != 0 {
+		return nil, nil, errKeySize
+	}
+
+	return key[:len(key)/2], key[len(key)/2:], nil
+}
+
+// SIV is a SIV-CMAC AEAD, or a PMAC-SIV one if created by NewPMACSIV. It
+// implements cipher.AEAD.
+//
+// An SIV is safe for concurrent use by multiple goroutines, in any mix of
+// Seal, Open and their variants: each operation uses its own scratch space.
+// Only Reset and Close require that no other method is in use.
+//
+// Scratch space, including the keystream, is pooled by each SIV and reused
+// across goroutines, so once the pool is warm Seal and Open make no
+// allocations of their own. A Sealer instead gives one goroutine scratch space
+// of its own.
+type SIV struct {
+	enc, mac     cipher.Block
+	k1, k2       []byte // CMAC subkeys for mac
+	k3, k4       []byte // subkeys for Outer, the next doublings of k2
+	pmac         bool
+	l            [][]byte // PMAC offsets for mac, if pmac is set
+	linv         []byte
+	alg          func([]byte) (cipher.Block, error)
+	newCTR       func(cipher.Block, []byte) cipher.Stream // nil for the default
+	closed       atomic.Bool
+	magic        []byte
+	nonceSize    int
+	chunkSize    int // for SealTo; 0 for the default
+	ctrWorkers   int // for WithParallelCTR
+	ctrMinSize   int
+	maxOpenLen   int // 0 for no limit
+	weakKeyCheck bool
+	strict       bool
+	exactNonce   bool
+	pool         sync.Pool // of *scratch
+}
+
+// Close wipes the AEAD's key material. Afterwards Open and the other
+// error-returning methods return ErrClosed, and Seal panics. Close must not be
+// called while any other method is in use.
+//
+// The MAC subkeys, and the pooled scratch space in which Seal and Open hold
+// intermediate values, are zeroed. Sealers, Encrypters and Decrypters fail
+// once the AEAD is closed, but their own scratch space and copies of the
+// subkeys are not wiped, and are left to the collector. The expanded keys
+// inside the block ciphers cannot be wiped either, as cipher.Block offers no
+// way to, so Close drops the AEAD's references to them instead; callers who
+// need those wiped too should use NewWithCiphers with block ciphers they can
+// clear themselves.
+func (s *SIV) Close() error {
+	s.closed.Store(true)
+	s.wipeSubkeys()
+
+	b := closedBlock(s.mac.BlockSize())
+	s.mac, s.enc = b, b
+
+	for {
+		sc, _ := s.pool.Get().(*scratch)
+		if sc == nil {
+			break
+		}
+		sc.wipe()
+	}
+
+	return nil
+}
+
+// wipeSubkeys zeroes the MAC subkeys derived from the current key.
+func (s *SIV) wipeSubkeys() {
+	for _, v := range append([][]byte{s.k1, s.k2, s.k3, s.k4, s.linv}, s.l...) {
+		for i := range v {
+			v[i] = 0
+		}
+	}
+}
+
+// closedBlock replaces the block ciphers of a closed AEAD.
+type closedBlock int
+
+func (b closedBlock) BlockSize() int {
+	return int(b)
+}
+
+func (b closedBlock) Encrypt(dst, src []byte) {
+	panic("siv: use of closed AEAD")
+}
+
+func (b closedBlock) Decrypt(dst, src []byte) {
+	panic("siv: use of closed AEAD")
+}
+
+// Reset re-keys the AEAD in place with the given key, which must be valid for
+// the algorithm it was created with. If the key is invalid, an error is
+// returned and the AEAD is left unchanged. Reset must not be called while any
+// other method is in use, and fails for AEADs from NewWithCiphers.
+func (s *SIV) Reset(key []byte) error {
+	if s.closed.Load() {
+		return ErrClosed
+	}
+
+	if s.alg == nil {
+		return errNoAlg
+	}
+
+	macKey, encKey, err := SplitKey(key)
+	if err != nil {
+		return err
+	}
+
+	if s.weakKeyCheck {
+		if err := checkWeakKey(macKey, encKey); err != nil {
+			return err
+		}
+	}
+
+	mac, err := s.alg(macKey)
+	if err != nil {
+		return err
+	}
+
+	enc, err := s.alg(encKey)
+	if err != nil {
+		return err
+	}
+
+	return s.setCiphers(mac, enc)
+}
+
+// setCiphers keys the AEAD with the given block ciphers, deriving the MAC
+// subkeys, or returns an error without changing it.
+func (s *SIV) setCiphers(mac, enc cipher.Block) error {
+	if size := mac.BlockSize(); size != 8 && size != 16 || enc.BlockSize() != size {
+		return errBlockSize
+	}
+
+	// the previous key's subkeys are wiped rather than left to the collector
+	s.wipeSubkeys()
+
+	s.enc, s.mac = enc, mac
+	s.k1, s.k2 = cmac.Subkeys(mac)
+	s.k3, s.k4 = outerSubkeys(s.k2)
+	if s.pmac {
+		s.l, s.linv = pmacOffsets(mac)
+	}
+
+	return nil
+}
+
+// checkWeakKey returns an error if the key halves are all zero or equal.
+func checkWeakKey(macKey, encKey []byte) error {
+	var or byte
+	for _, v := range macKey {
+		or |= v
+	}
+	for _, v := range encKey {
+		or |= v
+	}
+
+	if or == 0 {
+		return errZeroKey
+	}
+
+	if subtle.ConstantTimeCompare(macKey, encKey) == 1 {
+		return errEqualHalves
+	}
+
+	return nil
 }
 
-type siv struct {
-	enc, mac cipher.Block
+// NonceSize returns the nonce size configured with WithNonceSize, or 0 if none
+// was. If it is positive, Seal and Open require nonces of exactly that many
+// bytes: Seal panics on a nonce of any other length, and Open returns an
+// error.
+func (s *SIV) NonceSize() int {
+	return s.nonceSize
 }
 
-func (*siv) NonceSize() int {
-	return 0
+// Overhead returns the difference between the lengths of a ciphertext and its
+// plaintext: the synthetic IV plus any framing added by the AEAD's options.
+func (s *SIV) Overhead() int {
+	return len(s.magic) + s.tagSize()
 }
 
-func (s *siv) Overhead() int {
+// tagSize returns the size of the synthetic IV.
+func (s *SIV) tagSize() int {
 	return s.mac.BlockSize()
 }
 
-func (s *siv) Open(dst, nonce, ciphertext, data []byte) ([]byte, error) {
-	v, ciphertext := ciphertext[:s.Overhead()], ciphertext[s.Overhead():]
-	plaintext := make([]byte, len(ciphertext))
-	ctr := cipher.NewCTR(s.enc, ctr(v))
-	ctr.XORKeyStream(plaintext, ciphertext)
+// tag returns the synthetic IV of a ciphertext for which Valid is true.
+func (s *SIV) tag(ciphertext []byte) []byte {
+	return ciphertext[len(s.magic) : len(s.magic)+s.tagSize()]
+}
 
-	h, _ := cmac.NewWithCipher(s.mac)
-	vP := s2v(h, data, nonce, plaintext)
+// Valid reports whether ciphertext is long enough to have been produced by this
+// AEAD. It is a structural check only and says nothing about whether the
+// ciphertext is authentic; only Open can determine that.
+func (s *SIV) Valid(ciphertext []byte) bool {
+	return len(ciphertext) >= s.Overhead() && bytes.HasPrefix(ciphertext, s.magic)
+}
 
-	if subtle.ConstantTimeCompare(v, vP) != 1 {
-		return nil, errOpen
+// DecryptedLen returns the length of the plaintext Open would return for a
+// ciphertext of the given length, taking into account any framing added by
+// the AEAD's options. It returns an error if no ciphertext of that length
+// could be valid.
+func (s *SIV) DecryptedLen(ciphertextLen int) (int, error) {
+	if ciphertextLen < s.Overhead() {
+		return 0, errCiphertextSize
 	}
+	return ciphertextLen - s.Overhead(), nil
+}
 
-	return append(dst, plaintext...), nil
+// tooLong reports whether the plaintext of ciphertext would exceed the limit
+// set with WithMaxOpenLen.
+func (s *SIV) tooLong(ciphertext []byte) bool {
+	return s.maxOpenLen > 0 && len(ciphertext)-s.Overhead() > s.maxOpenLen
+}
+
+// OpenMemoryEstimate returns the number of bytes Open allocates for a
+// ciphertext of the given length when dst has no spare capacity: the plaintext
+// itself, plus the scratch space used to decrypt and authenticate it if none
+// is pooled. It returns 0 for lengths Open would reject.
+func (s *SIV) OpenMemoryEstimate(ciphertextLen int) int {
+	if ciphertextLen < s.Overhead() {
+		return 0
+	}
+	return ciphertextLen - s.Overhead() + scratchSize(s.mac.BlockSize())
 }
 
-func (s *siv) Seal(dst, nonce, plaintext, data []byte) []byte {
-	h, _ := cmac.NewWithCipher(s.mac)
+// Open implements cipher.AEAD. As with Seal, data is authenticated as a single
+// opaque associated data component; it is never split. Use OpenMulti for a
+// vector of components.
+//
+// The plaintext is appended to dst, in its spare capacity if there is enough.
+// To open in place, use ciphertext[:0] as dst.
+//
+// Any ciphertext, including one shorter than Overhead, is safe to pass: those
+// which could not have been sealed by this AEAD fail with ErrAuthentication,
+// the same error as a forgery, so that the two cannot be told apart.
+func (s *SIV) Open(dst, nonce, ciphertext, data []byte) ([]byte, error) {
+	return s.open(dst, ciphertext, nonce, data)
+}
 
-	v := s2v(h, data, nonce, plaintext)
+// OpenInto is like Open with no nonce, but decrypts into the start of dst
+// rather than appending to it, returning the length of the plaintext. dst may
+// be any caller-owned memory, such as a memory-mapped file. It returns an
+// error without writing anything if dst is too small. If the ciphertext is not
+// authentic, the region written is zeroed before ErrAuthentication is
+// returned, so dst must not be used unless OpenInto succeeds.
+func (s *SIV) OpenInto(dst, ciphertext, data []byte) (int, error) {
+	n, err := s.DecryptedLen(len(ciphertext))
+	if err != nil {
+		return 0, ErrAuthentication
+	}
 
-	ctr := cipher.NewCTR(s.enc, ctr(v))
-	result := make([]byte, len(v)+len(plaintext))
-	copy(result, v)
-	ctr.XORKeyStream(result[len(v):], plaintext)
+	if len(dst) < n {
+		return 0, errShortBuffer
+	}
 
-	return append(dst, result...)
+	plaintext, err := s.open(dst[:0:n], ciphertext, nil, data)
+	return len(plaintext), err
 }
 
-var (
-	errOpen = errors.New("message authentication failed")
-)
+// open opens ciphertext with the given associated data components, skipping
+// any that are nil, followed by nonce unless it is nil. Callers with no nonce
+// of their own pass nil, and so fail with errNonceSize on an AEAD which
+// requires one.
+func (s *SIV) open(dst, ciphertext, nonce []byte, ad ...[]byte) ([]byte, error) {
+	sc := s.getScratch()
+	defer s.putScratch(sc)
 
-func ctr(v []byte) []byte {
-	q := make([]byte, len(v))
-	copy(q, v)
-	q[len(q)-4] &= 0x7f
-	q[len(q)-8] &= 0x7f
-	return q
+	return s.openWith(sc, dst, ciphertext, nonce, ad)
 }
 
-func s2v(h hash.Hash, data ...[]byte) []byte {
-	d := make([]byte, h.BlockSize())
-	_, _ = h.Write(d)
-	d = h.Sum(d[:0])
-	h.Reset()
+// openWith is open using the given scratch space.
+func (s *SIV) openWith(sc *scratch, dst, ciphertext, nonce []byte, ad [][]byte) ([]byte, error) {
+	if err := s.checkNonce(nonce); err != nil {
+		return nil, err
+	}
 
-	for _, v := range data[:len(data)-1] {
-		if v == nil {
-			continue
-		}
+	if s.closed.Load() {
+		return nil, ErrClosed
+	}
 
-		dbl(d)
+	if !s.Valid(ciphertext) {
+		return nil, ErrAuthentication
+	}
 
-		_, _ = h.Write(v)
+	if s.tooLong(ciphertext) {
+		return nil, errMaxOpenLen
+	}
+
+	checkOverlap(dst, len(ciphertext)-s.Overhead(), ciphertext, ad, nonce)
+
+	ciphertext = ciphertext[len(s.magic):]
+	return s.openBody(sc, dst, ciphertext[:s.tagSize()], ciphertext[s.tagSize():], ad, nonce)
+}
+
+// openBody decrypts and authenticates body against the synthetic IV tag,
+// appending the plaintext to dst. The caller checks the inputs.
+func (s *SIV) openBody(sc *scratch, dst, tag, body []byte, ad [][]byte, nonce []byte) ([]byte, error) {
+	s.bind(sc)
+
+	ret, plaintext := s.decryptBody(sc, dst, tag, body)
+	return authenticate(ret, plaintext, sc.v, sc.s2v(s.magic, ad, nonce, plaintext))
+}
+
+// decryptBody decrypts body with the synthetic IV tag, appending the
+// unauthenticated plaintext to dst. It leaves a copy of tag in sc.v.
+func (s *SIV) decryptBody(sc *scratch, dst, tag, body []byte) (ret, plaintext []byte) {
+	// the tag is copied out first, as dst may overlap it
+	v := sc.v
+	copy(v, tag)
+
+	ret, plaintext = sliceForAppend(dst, len(body))
+	copy(plaintext, body)
+	s.xorKeyStream(sc, plaintext, v)
+
+	return ret, plaintext
+}
 
-		for i, v := range h.Sum(nil) {
-			d[i] ^= v
+// authenticate returns ret if the synthetic IV v matches the one recomputed
+// from the plaintext, and otherwise zeroes the plaintext and returns
+// ErrAuthentication.
+func authenticate(ret, plaintext, v, expected []byte) ([]byte, error) {
+	if subtle.ConstantTimeCompare(v, expected) != 1 {
+		for i := range plaintext {
+			plaintext[i] = 0
 		}
+		return nil, ErrAuthentication
+	}
+
+	return ret, nil
+}
 
-		h.Reset()
+// OpenExpect opens ciphertext like Open and then passes the associated data to
+// validate, returning its error if any. validate is only called once the
+// ciphertext has been authenticated, so it never sees unauthenticated data.
+func (s *SIV) OpenExpect(ciphertext, data []byte, validate func(ad []byte) error) ([]byte, error) {
+	plaintext, err := s.open(nil, ciphertext, nil, data)
+	if err != nil {
+		return nil, err
 	}
 
-	v := data[len(data)-1]
+	if err := validate(data); err != nil {
+		return nil, err
+	}
 
-	if len(v) >= h.BlockSize() {
-		// xorend
-		prefix := len(v) - len(d)
-		_, _ = h.Write(v[:prefix])
-		for i := range d {
-			d[i] ^= v[prefix+i]
-		}
-		_, _ = h.Write(d)
-	} else {
-		dbl(d)
+	return plaintext, nil
+}
 
-		// pad and xor
-		for i, v := range v {
-			d[i] ^= v
-		}
-		d[len(v)] ^= 0x80
+// Seal implements cipher.AEAD. data is authenticated as a single associated
+// data component and nonce, if not nil, as a second one following it. Use
+// SealMulti for a vector of components.
+//
+// The nonce is always the last component before the plaintext, as RFC 5297
+// section 3 specifies, so Seal(dst, nonce, plaintext, data) is equivalent to
+// SealMulti(dst, plaintext, data, nonce) and interoperates with
+// implementations which take the nonce as the final associated data.
+//
+// The ciphertext is appended to dst, in its spare capacity if there is enough.
+// To seal in place, use plaintext[:0] as dst with at least Overhead bytes of
+// capacity beyond the plaintext. Neither Seal nor Open allocates when dst has
+// room for the result.
+func (s *SIV) Seal(dst, nonce, plaintext, data []byte) []byte {
+	return s.mustSeal(dst, plaintext, nonce, data)
+}
+
+// seal seals plaintext with the given associated data components, skipping
+// any that are nil, followed by nonce unless it is nil. Callers with no nonce
+// of their own pass nil, and so fail with errNonceSize on an AEAD which
+// requires one.
+func (s *SIV) seal(dst, plaintext, nonce []byte, ad ...[]byte) ([]byte, error) {
+	sc := s.getScratch()
+	defer s.putScratch(sc)
+
+	return s.sealWith(sc, dst, plaintext, nonce, ad)
+}
+
+// mustSeal is seal for methods which, like Seal, cannot return an error.
+func (s *SIV) mustSeal(dst, plaintext, nonce []byte, ad ...[]byte) []byte {
+	ret, err := s.seal(dst, plaintext, nonce, ad...)
+	if err != nil {
+		panic("siv: " + err.Error())
+	}
+
+	return ret
+}
+
+// sealWith is seal using the given scratch space.
+func (s *SIV) sealWith(sc *scratch, dst, plaintext, nonce []byte, ad [][]byte) ([]byte, error) {
+	if err := s.checkNonce(nonce); err != nil {
+		return nil, err
+	}
+
+	if s.closed.Load() {
+		panic("siv: Seal called after Close")
+	}
+
+	checkOverlap(dst, s.Overhead()+len(plaintext), plaintext, ad, nonce)
+	s.bind(sc)
+
+	return s.sealBody(sc, dst, plaintext, sc.s2v(s.magic, ad, nonce, plaintext)), nil
+}
+
+// sealBody appends the synthetic IV v and the encryption of plaintext with it
+// to dst. The caller checks the inputs.
+func (s *SIV) sealBody(sc *scratch, dst, plaintext, v []byte) []byte {
+	// copy allows overlap, so when sealing in place the plaintext is moved
+	// into position before the synthetic IV is written over its start
+	ret, out := sliceForAppend(dst, s.Overhead()+len(plaintext))
+	ciphertext := out[s.Overhead():]
+	copy(ciphertext, plaintext)
+	copy(out[copy(out, s.magic):], v)
+	s.xorKeyStream(sc, ciphertext, v)
+
+	return ret
+}
+
+func (s *SIV) stream(v []byte) cipher.Stream {
+	if s.newCTR == nil {
+		return newCTR(s.enc, v)
+	}
+	return s.newCTR(s.enc, v)
+}
+
+// CiphertextEqual reports whether a and b are the same ciphertext. Because SIV
+// is deterministic, this is equivalent to comparing the plaintexts (and
+// associated data) they were sealed from. The comparison takes time
+// independent of the contents of a and b.
+func CiphertextEqual(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+var (
+	// ErrAuthentication is returned when a ciphertext cannot be opened, whether
+	// because it was tampered with or because the key or associated data do not
+	// match those it was sealed with.
+	ErrAuthentication = errors.New("message authentication failed")
+
+	// ErrClosed is returned when an AEAD is used after Close.
+	ErrClosed = errors.New("use of closed AEAD")
 
-		_, _ = h.Write(d)
+	errKeySize   = errors.New("invalid key size: must be two non-empty halves of equal length")
+	errBlockSize = errors.New("unsupported block size")
+
+	errCiphertextSize = errors.New("invalid ciphertext size")
+	errNilAlg         = errors.New("nil block cipher constructor")
+	errNilBlock       = errors.New("nil block cipher")
+	errNoAlg          = errors.New("no block cipher constructor to re-key with")
+	errMaxOpenLen     = errors.New("ciphertext exceeds maximum length")
+	errShortBuffer    = errors.New("destination too small")
+	errZeroKey        = errors.New("weak key: all zero")
+	errEqualHalves    = errors.New("weak key: MAC and encryption halves are equal")
+)
+
+// newMAC returns a new instance of the MAC used by S2V. It holds its own
+// copies of the subkeys, as those of the AEAD are wiped in place by Close and
+// Reset while it may still be in use.
+func (s *SIV) newMAC() hash.Hash {
+	if s.pmac {
+		l := make([][]byte, len(s.l))
+		for i, v := range s.l {
+			l[i] = append([]byte(nil), v...)
+		}
+		return newPMAC(s.mac, l, append([]byte(nil), s.linv...))
 	}
+	return cmac.New(s.mac, append([]byte(nil), s.k1...), append([]byte(nil), s.k2...))
+}
 
-	return h.Sum(d[:0])
+// subkeys returns the cached CMAC subkeys. It exists for tests.
+func (s *SIV) subkeys() (k1, k2 []byte) {
+	return s.k1, s.k2
 }
 
-func dbl(b []byte) {
-	shifted := (b[0] >> 7) == 1
-	shiftLeft(b)
-	if shifted {
-		b[len(b)-1] ^= 0x87
+// sliceForAppend extends in by n bytes, returning the whole slice and the
+// extension.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
 	}
+	tail = head[len(in):]
+	return
 }
 
-func shiftLeft(b []byte) {
-	overflow := byte(0)
-	for i := len(b) - 1; i >= 0; i-- {
-		v := b[i]
-		b[i] <<= 1
-		b[i] |= overflow
-		overflow = (v & 0x80) >> 7
+// CTRInitVector returns the initial CTR counter block derived from a synthetic
+// IV, as used internally to encrypt and decrypt. It panics unless tag is 8 or
+// 16 bytes, the synthetic IV sizes of the supported 64- and 128-bit block
+// ciphers.
+func CTRInitVector(tag []byte) []byte {
+	if len(tag) != 8 && len(tag) != 16 {
+		panic("siv: invalid tag size for CTRInitVector")
 	}
+	return ctr(tag)
+}
+
+func ctr(v []byte) []byte {
+	q := make([]byte, len(v))
+	copy(q, v)
+	maskCTR(q)
+	return q
+}
+
+// maskCTR clears the bits of a synthetic IV that RFC 5297 requires to be zero
+// in the initial counter block.
+func maskCTR(q []byte) {
+	q[len(q)-4] &= 0x7f
+	q[len(q)-8] &= 0x7f
 }
@@ -3,10 +3,22 @@ package siv
 import (
 	"bytes"
 	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
 	"encoding/hex"
+	"errors"
+	"io"
+	"sync"
 	"testing"
 )
 
+func TestNilAlg(t *testing.T) {
+	aead, err := New(make([]byte, 32), nil)
+	if err != errNilAlg {
+		t.Errorf("Error was %v, but expected %v (AEAD %v)", err, errNilAlg, aead)
+	}
+}
+
 func TestBadKeySize(t *testing.T) {
 	aead, err := New(make([]byte, 16), aes.NewCipher)
 	if err == nil {
@@ -128,3 +140,1189 @@ func BenchmarkSeal(b *testing.B) {
 		aead.Seal(nil, nil, plaintext, data)
 	}
 }
+
+func TestCiphertextEqual(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := aead.Seal(nil, nil, plaintext, data)
+	b := aead.Seal(nil, nil, plaintext, data)
+
+	if !CiphertextEqual(a, b) {
+		t.Errorf("Ciphertexts %x and %x were not equal", a, b)
+	}
+
+	plaintext[0] ^= 1
+	c := aead.Seal(nil, nil, plaintext, data)
+
+	if CiphertextEqual(a, c) {
+		t.Errorf("Ciphertexts %x and %x were equal", a, c)
+	}
+
+	if CiphertextEqual(a, a[:len(a)-1]) {
+		t.Errorf("Ciphertexts %x and %x were equal", a, a[:len(a)-1])
+	}
+}
+
+func TestOpenExpect(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data := []byte("v2:payload")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+
+	errVersion := errors.New("bad version")
+	expect := func(version string) func([]byte) error {
+		return func(ad []byte) error {
+			if !bytes.HasPrefix(ad, []byte(version+":")) {
+				return errVersion
+			}
+			return nil
+		}
+	}
+
+	actual, err := aead.OpenExpect(ciphertext, data, expect("v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+
+	actual, err = aead.OpenExpect(ciphertext, data, expect("v1"))
+	if err != errVersion {
+		t.Fatalf("Error was %v, but expected %v (plaintext %x)", err, errVersion, actual)
+	}
+}
+
+func TestOpenExpectUnauthenticated(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, []byte("v2:payload"))
+
+	called := false
+	_, err = aead.OpenExpect(ciphertext, []byte("v1:payload"), func([]byte) error {
+		called = true
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Ciphertext opened with the wrong associated data")
+	}
+
+	if called {
+		t.Error("Validation ran on unauthenticated associated data")
+	}
+}
+
+func TestKeySizeMismatch(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aes128, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aes256, err := New(append(key, key...), aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := aes256.Open(nil, nil, aes128.Seal(nil, nil, plaintext, data), data)
+	if err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v (plaintext %x)", err, ErrAuthentication, actual)
+	}
+
+	actual, err = aes128.Open(nil, nil, aes256.Seal(nil, nil, plaintext, data), data)
+	if err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v (plaintext %x)", err, ErrAuthentication, actual)
+	}
+}
+
+func TestReset(t *testing.T) {
+	oldKey, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	newKey, _ := hex.DecodeString("7f7e7d7c7b7a79787776757473727170404142434445464708090a0b0c0d0e0f")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(oldKey, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldCiphertext := aead.Seal(nil, nil, plaintext, data)
+
+	if err := aead.Reset(newKey); err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := New(newKey, aes.NewCipher)
+	newCiphertext := other.Seal(nil, nil, plaintext, data)
+
+	actual, err := aead.Open(nil, nil, newCiphertext, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+
+	actual, err = aead.Open(nil, nil, oldCiphertext, data)
+	if err == nil {
+		t.Fatalf("Plaintext returned instead of error: %x", actual)
+	}
+}
+
+func TestResetBadKeySize(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+
+	if err := aead.Reset(make([]byte, 16)); err == nil {
+		t.Fatal("Reset succeeded with a bad key size")
+	}
+
+	if _, err := aead.Open(nil, nil, ciphertext, data); err != nil {
+		t.Errorf("Failed Reset changed the key: %v", err)
+	}
+}
+
+func TestSplitKey(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	macKey, encKey, err := SplitKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, want := macKey, key[:16]; !bytes.Equal(v, want) {
+		t.Errorf("MAC key was %x, but expected %x", v, want)
+	}
+
+	if v, want := encKey, key[16:]; !bytes.Equal(v, want) {
+		t.Errorf("Encryption key was %x, but expected %x", v, want)
+	}
+}
+
+func TestSplitKeyBadKeySize(t *testing.T) {
+	for _, n := range []int{0, 1, 33} {
+		if _, _, err := SplitKey(make([]byte, n)); err != errKeySize {
+			t.Errorf("Error for %d-byte key was %v, but expected %v", n, err, errKeySize)
+		}
+
+		if _, err := New(make([]byte, n), aes.NewCipher); err != errKeySize {
+			t.Errorf("Error for %d-byte key was %v, but expected %v", n, err, errKeySize)
+		}
+	}
+}
+
+func BenchmarkSeal16(b *testing.B) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext := make([]byte, 16)
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.SetBytes(16)
+
+	for i := 0; i < b.N; i++ {
+		aead.Seal(nil, nil, plaintext, data)
+	}
+}
+
+func TestSealBlock(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// forcing a CTR stream disables the single-block path
+	general, err := New(key, aes.NewCipher, WithCTR(newCTR))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for n := 0; n <= aes.BlockSize; n++ {
+		plaintext := bytes.Repeat([]byte{byte(n)}, n)
+
+		actual := aead.Seal(nil, nil, plaintext, data)
+		expected := general.Seal(nil, nil, plaintext, data)
+
+		if !bytes.Equal(actual, expected) {
+			t.Errorf("Ciphertext of %d bytes was %x, but expected %x", n, actual, expected)
+		}
+
+		inPlace := append([]byte(nil), plaintext...)
+		if actual := aead.Seal(inPlace[:0], nil, inPlace, data); !bytes.Equal(actual, expected) {
+			t.Errorf("In-place ciphertext of %d bytes was %x, but expected %x", n, actual, expected)
+		}
+	}
+}
+
+func TestNewFromReader(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	ciphertext, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	aead, err := NewFromReader(bytes.NewReader(key), len(key), aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := aead.Seal(nil, nil, plaintext, data)
+	if !bytes.Equal(actual, ciphertext) {
+		t.Errorf("Ciphertext was %x, but expected %x", actual, ciphertext)
+	}
+}
+
+func TestNewFromReaderShortRead(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	aead, err := NewFromReader(bytes.NewReader(key[:20]), len(key), aes.NewCipher)
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("Error was %v, but expected %v (AEAD %v)", err, io.ErrUnexpectedEOF, aead)
+	}
+}
+
+func TestClose(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+
+	if err := aead.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := aead.Open(nil, nil, ciphertext, data); err != ErrClosed {
+		t.Errorf("Open error was %v, but expected %v", err, ErrClosed)
+	}
+
+	if _, err := aead.OpenExpect(ciphertext, data, func([]byte) error { return nil }); err != ErrClosed {
+		t.Errorf("OpenExpect error was %v, but expected %v", err, ErrClosed)
+	}
+
+	if err := aead.StreamSeal(new(bytes.Buffer), bytes.NewReader(plaintext), data); err != ErrClosed {
+		t.Errorf("StreamSeal error was %v, but expected %v", err, ErrClosed)
+	}
+
+	if err := aead.Reset(key); err != ErrClosed {
+		t.Errorf("Reset error was %v, but expected %v", err, ErrClosed)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Seal did not panic after Close")
+		}
+	}()
+
+	aead.Seal(nil, nil, plaintext, data)
+}
+
+func TestCloseWipes(t *testing.T) {
+	for _, newAEAD := range []func([]byte, func([]byte) (cipher.Block, error), ...Option) (*SIV, error){New, NewPMACSIV} {
+		aead, err := newAEAD(bytes.Repeat([]byte{1}, 32), aes.NewCipher)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// leave used scratch space in the pool
+		aead.Seal(nil, nil, []byte("plaintext"), nil)
+		sc := aead.getScratch()
+		aead.putScratch(sc)
+
+		k1, k2 := aead.subkeys()
+		_ = aead.Close()
+
+		wiped := append([][]byte{k1, k2, aead.k3, aead.k4, aead.linv}, aead.l...)
+		if !raceEnabled {
+			// sync.Pool drops items under the race detector
+			wiped = append(wiped, sc.d, sc.v, sc.ks, sc.p.digest)
+		}
+
+		for _, v := range wiped {
+			if !bytes.Equal(v, make([]byte, len(v))) {
+				t.Errorf("Key material was %x after Close, but expected zeroes", v)
+			}
+		}
+
+		if _, ok := aead.mac.(closedBlock); !ok {
+			t.Errorf("MAC cipher was %T after Close, but expected closedBlock", aead.mac)
+		}
+
+		if _, ok := aead.enc.(closedBlock); !ok {
+			t.Errorf("CTR cipher was %T after Close, but expected closedBlock", aead.enc)
+		}
+
+		if n := aead.OpenMemoryEstimate(100); n == 0 {
+			t.Error("OpenMemoryEstimate was 0 after Close")
+		}
+	}
+}
+
+func TestResetWipes(t *testing.T) {
+	aead, _ := New(bytes.Repeat([]byte{1}, 32), aes.NewCipher)
+	k1, k2 := aead.subkeys()
+
+	if err := aead.Reset(bytes.Repeat([]byte{2}, 32)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(k1, make([]byte, 16)) || !bytes.Equal(k2, make([]byte, 16)) {
+		t.Errorf("Old subkeys were %x and %x after Reset, but expected zeroes", k1, k2)
+	}
+}
+
+func TestValid(t *testing.T) {
+	aead, _ := New(make([]byte, 32), aes.NewCipher)
+
+	for _, v := range []struct {
+		len   int
+		valid bool
+	}{
+		{0, false},
+		{aead.Overhead() - 1, false},
+		{aead.Overhead(), true},
+		{aead.Overhead() + 14, true},
+	} {
+		if actual := aead.Valid(make([]byte, v.len)); actual != v.valid {
+			t.Errorf("Valid for %d bytes was %v, but expected %v", v.len, actual, v.valid)
+		}
+	}
+}
+
+func TestCTRInitVector(t *testing.T) {
+	// https://tools.ietf.org/html/rfc5297#appendix-A.1
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	ciphertext, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+	expected, _ := hex.DecodeString("85632d07c6e8f37f150acd320a2ecc93")
+
+	iv := CTRInitVector(ciphertext[:16])
+	if !bytes.Equal(iv, expected) {
+		t.Errorf("CTR IV was %x, but expected %x", iv, expected)
+	}
+
+	block, _ := aes.NewCipher(key[16:])
+	actual := make([]byte, len(plaintext))
+	cipher.NewCTR(block, iv).XORKeyStream(actual, ciphertext[16:])
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+}
+
+func TestCTRInitVectorTagSize(t *testing.T) {
+	if iv := CTRInitVector(make([]byte, 8)); len(iv) != 8 {
+		t.Errorf("CTR IV for an 8-byte tag was %d bytes, but expected 8", len(iv))
+	}
+
+	for _, n := range []int{0, 7, 9, 15, 17, 32} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("CTRInitVector did not panic on a %d-byte tag", n)
+				}
+			}()
+
+			CTRInitVector(make([]byte, n))
+		}()
+	}
+}
+
+func TestOpenAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items under the race detector")
+	}
+
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext := make([]byte, 16)
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+	dst := make([]byte, 0, len(plaintext))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := aead.Open(dst, nil, ciphertext, data); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if allocs != 0 {
+		t.Errorf("Open of a %d-byte ciphertext made %v allocations, but expected 0", len(ciphertext), allocs)
+	}
+}
+
+func TestSealAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items under the race detector")
+	}
+
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	nonce, _ := hex.DecodeString("09f911029d74e35bd84156c5635688c0")
+	plaintext := make([]byte, 16)
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := make([]byte, 0, len(plaintext)+aead.Overhead())
+	buf := make([]byte, len(plaintext), len(plaintext)+aead.Overhead())
+
+	for name, seal := range map[string]func(){
+		"Seal":          func() { aead.Seal(dst, nonce, plaintext, data) },
+		"Seal in place": func() { aead.Seal(buf[:0], nonce, buf, data) },
+		"SealMulti":     func() { aead.SealMulti(dst, plaintext, data, nonce) },
+	} {
+		if allocs := testing.AllocsPerRun(100, seal); allocs != 0 {
+			t.Errorf("%s of a %d-byte plaintext made %v allocations, but expected 0", name, len(plaintext), allocs)
+		}
+	}
+}
+
+func TestSealAllocsLarge(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items under the race detector")
+	}
+
+	aead, err := New(make([]byte, 32), aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{257, 4096, 65536} {
+		plaintext := make([]byte, n)
+		ciphertext := aead.Seal(nil, nil, plaintext, nil)
+		dst := make([]byte, 0, len(ciphertext))
+
+		if allocs := testing.AllocsPerRun(100, func() { aead.Seal(dst, nil, plaintext, nil) }); allocs != 0 {
+			t.Errorf("Seal of a %d-byte plaintext made %v allocations, but expected 0", n, allocs)
+		}
+
+		if allocs := testing.AllocsPerRun(100, func() { _, _ = aead.Open(dst, nil, ciphertext, nil) }); allocs != 0 {
+			t.Errorf("Open of a %d-byte ciphertext made %v allocations, but expected 0", n, allocs)
+		}
+	}
+}
+
+func TestOpenInPlace(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 14, 16, 100} {
+		plaintext := bytes.Repeat([]byte{byte(n)}, n)
+		ciphertext := aead.Seal(nil, nil, plaintext, data)
+
+		actual, err := aead.Open(ciphertext[:0], nil, ciphertext, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual, plaintext) {
+			t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+		}
+	}
+}
+
+func TestSealInPlace(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 14, 16, 100, 1000} {
+		plaintext := bytes.Repeat([]byte{byte(n)}, n)
+		expected := aead.Seal(nil, nil, plaintext, data)
+
+		buf := make([]byte, n, n+aead.Overhead())
+		copy(buf, plaintext)
+
+		if actual := aead.Seal(buf[:0], nil, buf, data); !bytes.Equal(actual, expected) {
+			t.Errorf("Ciphertext of %d bytes sealed in place was %x, but expected %x", n, actual, expected)
+		}
+	}
+}
+
+func BenchmarkOpen32(b *testing.B) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext := make([]byte, 16)
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+	dst := make([]byte, 0, len(plaintext))
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(ciphertext)))
+
+	for i := 0; i < b.N; i++ {
+		_, _ = aead.Open(dst, nil, ciphertext, data)
+	}
+}
+
+func TestBlockBoundary(t *testing.T) {
+	// computed with OpenSSL's AES-128-SIV
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []struct {
+		plaintext, ciphertext string
+	}{
+		{
+			"000102030405060708090a0b0c0d0e",
+			"dfaaad71568fa4e0924cf82347c01821b7e03e12aba97383c20df4ba6dc7a1",
+		},
+		{
+			"000102030405060708090a0b0c0d0e0f",
+			"9892bd33bd55f7e955dbd2cbeab4a927388d7c017340b270c47028855ffd0d4f",
+		},
+		{
+			"000102030405060708090a0b0c0d0e0f10",
+			"ed505d11e5979ac81113e11c897539e7cddb21c191b41eec46f930cf7fb5aa161c",
+		},
+		{
+			"000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			"d4ba92d59d0a52978f07a732ce915e700649a86d3754c6e78a804239d3c8bac45bf3f9a9cc3fa3ab995766f3e548427b",
+		},
+		{
+			"000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f",
+			"147438023d0a34727b0f6c993c7572a6e2b353dc6c9d38aeb0dceceb17914978872c1c0b0b9cac160dedf1b0613e63916eaeb14c37bd566146a6f97161bbd29e",
+		},
+	} {
+		plaintext, _ := hex.DecodeString(v.plaintext)
+		ciphertext, _ := hex.DecodeString(v.ciphertext)
+
+		actual := aead.Seal(nil, nil, plaintext, data)
+		if !bytes.Equal(actual, ciphertext) {
+			t.Errorf("Ciphertext of %d bytes was %x, but expected %x", len(plaintext), actual, ciphertext)
+		}
+
+		actual, err := aead.Open(nil, nil, ciphertext, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual, plaintext) {
+			t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+		}
+	}
+}
+
+func TestConcurrentSealOpen(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintexts := make([][]byte, 64)
+	ciphertexts := make([][]byte, len(plaintexts))
+	for i := range plaintexts {
+		plaintexts[i] = bytes.Repeat([]byte{byte(i)}, i)
+		ciphertexts[i] = aead.Seal(nil, nil, plaintexts[i], data)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			for i, plaintext := range plaintexts {
+				if actual := aead.Seal(nil, nil, plaintext, data); !bytes.Equal(actual, ciphertexts[i]) {
+					t.Errorf("Ciphertext was %x, but expected %x", actual, ciphertexts[i])
+				}
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			for i, ciphertext := range ciphertexts {
+				actual, err := aead.Open(nil, nil, ciphertext, data)
+				if err != nil {
+					t.Error(err)
+				} else if !bytes.Equal(actual, plaintexts[i]) {
+					t.Errorf("Plaintext was %x, but expected %x", actual, plaintexts[i])
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestOpenMemoryEstimate(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scratch := (7 + ctrBatchBlocks) * aes.BlockSize
+	cases := []struct {
+		ciphertextLen, expected int
+	}{
+		{-1, 0},
+		{0, 0},
+		{15, 0},
+		{16, scratch},
+		{30, 14 + scratch},
+		{1<<20 + 16, 1<<20 + scratch},
+	}
+
+	for _, c := range cases {
+		if v := aead.OpenMemoryEstimate(c.ciphertextLen); v != c.expected {
+			t.Errorf("Estimate for %d bytes was %d, but expected %d", c.ciphertextLen, v, c.expected)
+		}
+	}
+
+	magic, err := New(key, aes.NewCipher, WithMagic([]byte("SIV1")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := magic.OpenMemoryEstimate(19); v != 0 {
+		t.Errorf("Estimate for 19 bytes with a magic was %d, but expected 0", v)
+	}
+
+	if v, expected := magic.OpenMemoryEstimate(34), 14+scratch; v != expected {
+		t.Errorf("Estimate for 34 bytes with a magic was %d, but expected %d", v, expected)
+	}
+}
+
+func TestBlockSizes(t *testing.T) {
+	for _, bs := range blockSizes {
+		key, _ := hex.DecodeString(bs.key + bs.key)
+		data := []byte("associated data")
+
+		aead, err := New(key, bs.alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v := aead.Overhead(); v != bs.size {
+			t.Errorf("Overhead with %d-byte blocks was %d, but expected %d", bs.size, v, bs.size)
+		}
+
+		for n := 0; n <= 3*bs.size+1; n++ {
+			plaintext := bytes.Repeat([]byte{byte(n)}, n)
+			ciphertext := aead.Seal(nil, nil, plaintext, data)
+
+			actual, err := aead.Open(nil, nil, ciphertext, data)
+			if err != nil {
+				t.Errorf("Open of %d bytes with %d-byte blocks failed: %v", n, bs.size, err)
+			} else if !bytes.Equal(actual, plaintext) {
+				t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+			}
+		}
+	}
+}
+
+func TestTripleDES(t *testing.T) {
+	// computed independently using OpenSSL's DES-EDE3 CMAC and ECB
+	key := make([]byte, 48)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, des.NewTripleDESCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []struct {
+		len        int
+		ciphertext string
+	}{
+		{0, "c4e9336ab273dde5"},
+		{7, "fffed2afedfa714dcf310346e63f2e"},
+		{8, "a94a3fb93327ea760f1b5abac46b128c"},
+		{9, "6478340344493a074a68b183d3554ae773"},
+		{14, "b9b436bbe258910bbf8c7f02a522a3aeb894d0fb1dd8"},
+		{47, "30e3770d98df4ee519c2bbe3dfbfdb002e8ca993204b2361ac65882ef88c9cb82180a7f8dedfa6d74a2b4aaf39a0e3d5358f296180351a"},
+	} {
+		plaintext := make([]byte, v.len)
+		for i := range plaintext {
+			plaintext[i] = byte(i * 7)
+		}
+		expected, _ := hex.DecodeString(v.ciphertext)
+
+		if actual := aead.Seal(nil, nil, plaintext, data); !bytes.Equal(actual, expected) {
+			t.Errorf("Ciphertext of %d bytes was %x, but expected %x", v.len, actual, expected)
+		}
+	}
+}
+
+func TestUnsupportedBlockSize(t *testing.T) {
+	newBlock := func(key []byte) (cipher.Block, error) {
+		return stubBlock(len(key)), nil
+	}
+
+	for _, n := range []int{4, 12, 32} {
+		if _, err := New(make([]byte, 2*n), newBlock); err != errBlockSize {
+			t.Errorf("Error with %d-byte blocks was %v, but expected %v", n, err, errBlockSize)
+		}
+	}
+}
+
+// stubBlock is a cipher.Block of the given size which is only usable for
+// constructing an AEAD.
+type stubBlock int
+
+func (b stubBlock) BlockSize() int { return int(b) }
+
+func (b stubBlock) Encrypt(dst, src []byte) { copy(dst, src) }
+
+func (b stubBlock) Decrypt(dst, src []byte) { copy(dst, src) }
+
+func FuzzOpenArbitrary(f *testing.F) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	ciphertext, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	f.Add([]byte{}, []byte{})
+	f.Add(make([]byte, 1), data)
+	f.Add(make([]byte, 15), data)
+	f.Add(make([]byte, 16), []byte{})
+	f.Add(ciphertext, data)
+	f.Add(ciphertext[:29], data)
+	f.Add(bytes.Repeat([]byte{0xa5}, 100), []byte(nil))
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, ciphertext, data []byte) {
+		plaintext, err := aead.Open(nil, nil, ciphertext, data)
+		if err != nil {
+			if plaintext != nil {
+				t.Errorf("Plaintext %x returned along with error %v", plaintext, err)
+			}
+			return
+		}
+
+		if len(plaintext) != len(ciphertext)-aead.Overhead() {
+			t.Errorf("Plaintext was %d bytes, but expected %d", len(plaintext), len(ciphertext)-aead.Overhead())
+		}
+	})
+}
+
+func TestNonceOrdering(t *testing.T) {
+	// https://tools.ietf.org/html/rfc5297#appendix-A.2, with the nonce as
+	// the last associated data component; the single-component vectors were
+	// computed with OpenSSL's AES-SIV
+	key, _ := hex.DecodeString("7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f")
+	ad1, _ := hex.DecodeString("00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100")
+	ad2, _ := hex.DecodeString("102030405060708090a0")
+	nonce, _ := hex.DecodeString("09f911029d74e35bd84156c5635688c0")
+	plaintext, _ := hex.DecodeString("7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553")
+	rfc, _ := hex.DecodeString("7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17dba77ceb094fa663b7a3f748ba8af829ea64ad544a272e9c485b62a3fd5c0d")
+	expected, _ := hex.DecodeString("85825e22e90cf2ddda2c548dc7c1b6310dcdaca0cebf9dc6cb90583f5bf1506e02cd48832b00e4e598b2b22a53e6199d4df0c1666a35a0433b250dc134d776")
+	swapped, _ := hex.DecodeString("2eb54e91c7ff66e568c974ff7e45dae692cc57d47f510e0904a3ff573dccf0eb0e9064efb1e78716bc1c81a35e4050869d13920b0f01df87b332a77b55c4b0")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := aead.SealMulti(nil, plaintext, ad1, ad2, nonce); !bytes.Equal(actual, rfc) {
+		t.Errorf("Ciphertext was %x, but expected %x", actual, rfc)
+	}
+
+	actual := aead.Seal(nil, nonce, plaintext, ad1)
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("Ciphertext was %x, but expected %x", actual, expected)
+	}
+
+	if multi := aead.SealMulti(nil, plaintext, ad1, nonce); !bytes.Equal(multi, actual) {
+		t.Errorf("SealMulti with the nonce last was %x, but Seal was %x", multi, actual)
+	}
+
+	if v := aead.Seal(nil, ad1, plaintext, nonce); !bytes.Equal(v, swapped) || bytes.Equal(v[:16], actual[:16]) {
+		t.Errorf("Ciphertext with data and nonce swapped was %x, but expected %x", v, swapped)
+	}
+}
+
+func TestDataEqualsPlaintext(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 14, 16, 100} {
+		plaintext := bytes.Repeat([]byte{byte(n)}, n)
+		data := append([]byte{}, plaintext...)
+
+		ciphertext := aead.Seal(nil, nil, plaintext, data)
+
+		actual, err := aead.Open(nil, nil, ciphertext, data)
+		if err != nil {
+			t.Fatalf("Open of %d bytes failed: %v", n, err)
+		}
+
+		if !bytes.Equal(actual, plaintext) {
+			t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+		}
+
+		// the same contents as a single slice, read-only, are also fine
+		if v := aead.Seal(nil, nil, plaintext, plaintext); !bytes.Equal(v, ciphertext) {
+			t.Errorf("Ciphertext with aliased data was %x, but expected %x", v, ciphertext)
+		}
+	}
+}
+
+func TestPlaintextAsData(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+
+	actual, err := aead.Open(nil, nil, ciphertext, plaintext)
+	if err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v (plaintext %x)", err, ErrAuthentication, actual)
+	}
+
+	// nor is a ciphertext sealed with the plaintext as data interchangeable
+	// with one sealed with different data
+	if v := aead.Seal(nil, nil, plaintext, plaintext); bytes.Equal(v, ciphertext) {
+		t.Errorf("Ciphertexts with different data were both %x", v)
+	}
+}
+
+func TestOpenInto(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext := bytes.Repeat([]byte("plaintext"), 10)
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+
+	for _, size := range []int{len(plaintext), len(plaintext) + 10} {
+		dst := bytes.Repeat([]byte{0xff}, size)
+
+		n, err := aead.OpenInto(dst, ciphertext, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n != len(plaintext) || !bytes.Equal(dst[:n], plaintext) {
+			t.Errorf("Plaintext was %x, but expected %x", dst[:n], plaintext)
+		}
+
+		if !bytes.Equal(dst[n:], bytes.Repeat([]byte{0xff}, size-n)) {
+			t.Errorf("Bytes after the plaintext were overwritten: %x", dst[n:])
+		}
+	}
+
+	dst := bytes.Repeat([]byte{0xff}, len(plaintext)-1)
+	if n, err := aead.OpenInto(dst, ciphertext, data); err != errShortBuffer {
+		t.Errorf("Error for a short buffer was %v, but expected %v (%d bytes)", err, errShortBuffer, n)
+	}
+
+	if !bytes.Equal(dst, bytes.Repeat([]byte{0xff}, len(dst))) {
+		t.Errorf("Short buffer was written: %x", dst)
+	}
+}
+
+func TestOpenIntoTampered(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext := bytes.Repeat([]byte("plaintext"), 10)
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+	ciphertext[len(ciphertext)-1] ^= 1
+
+	dst := bytes.Repeat([]byte{0xff}, len(plaintext)+1)
+	if n, err := aead.OpenInto(dst, ciphertext, data); err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v (%d bytes)", err, ErrAuthentication, n)
+	}
+
+	if expected := append(make([]byte, len(plaintext)), 0xff); !bytes.Equal(dst, expected) {
+		t.Errorf("Buffer was %x after a failed open, but expected %x", dst, expected)
+	}
+
+	if n, err := aead.OpenInto(dst, ciphertext[:15], data); err != ErrAuthentication {
+		t.Errorf("Error for a short ciphertext was %v, but expected %v (%d bytes)", err, ErrAuthentication, n)
+	}
+}
+
+func TestOpenWrongData(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+
+	sameLength := append([]byte(nil), data...)
+	sameLength[len(sameLength)-1] ^= 1
+
+	for name, wrong := range map[string][]byte{
+		"same length":  sameLength,
+		"shorter":      data[:len(data)-1],
+		"longer":       append(append([]byte(nil), data...), 0),
+		"a full block": data[:16],
+		"nil":          nil,
+		"empty":        {},
+	} {
+		if actual, err := aead.Open(nil, nil, ciphertext, wrong); err != ErrAuthentication {
+			t.Errorf("Error with %s data was %v, but expected %v (plaintext %x)", name, err, ErrAuthentication, actual)
+		}
+	}
+}
+
+func TestOpenNilVersusEmptyData(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nil data is no component at all, while empty data is an empty
+	// component, so the two are not interchangeable
+	withNil := aead.Seal(nil, nil, plaintext, nil)
+	withEmpty := aead.Seal(nil, nil, plaintext, []byte{})
+
+	if bytes.Equal(withNil, withEmpty) {
+		t.Fatalf("Ciphertexts with nil and empty data were both %x", withNil)
+	}
+
+	if actual, err := aead.Open(nil, nil, withNil, []byte{}); err != ErrAuthentication {
+		t.Errorf("Error opening nil data with empty data was %v, but expected %v (plaintext %x)", err, ErrAuthentication, actual)
+	}
+
+	if actual, err := aead.Open(nil, nil, withEmpty, nil); err != ErrAuthentication {
+		t.Errorf("Error opening empty data with nil data was %v, but expected %v (plaintext %x)", err, ErrAuthentication, actual)
+	}
+}
+
+func TestOpenTruncated(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	for _, opts := range [][]Option{nil, {WithMagic([]byte("SIV1"))}} {
+		aead, err := New(key, aes.NewCipher, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ciphertext := aead.Seal(nil, nil, nil, data)
+
+		opens := map[string]func([]byte) error{
+			"Open": func(c []byte) error {
+				_, err := aead.Open(nil, nil, c, data)
+				return err
+			},
+			"OpenMulti": func(c []byte) error {
+				_, err := aead.OpenMulti(nil, c, data)
+				return err
+			},
+			"OpenInto": func(c []byte) error {
+				_, err := aead.OpenInto(make([]byte, 16), c, data)
+				return err
+			},
+			"OpenAt": func(c []byte) error {
+				_, err := aead.OpenAt(c, data, 1)
+				return err
+			},
+			"OpenVerbose": func(c []byte) error {
+				_, _, _, err := aead.OpenVerbose(c, data)
+				return err
+			},
+			"VerifyStream": func(c []byte) error {
+				return aead.VerifyStream(bytes.NewReader(c), data)
+			},
+		}
+
+		for name, open := range opens {
+			for _, c := range [][]byte{nil, {}, ciphertext[:1], ciphertext[:len(ciphertext)-1]} {
+				if err := open(c); err != ErrAuthentication {
+					t.Errorf("%s of a %d-byte ciphertext returned %v, but expected %v", name, len(c), err, ErrAuthentication)
+				}
+			}
+		}
+	}
+}
+
+// opaqueBlock hides the concrete type of a block cipher, as an HSM-backed
+// implementation would.
+type opaqueBlock struct {
+	b cipher.Block
+}
+
+func (o opaqueBlock) BlockSize() int { return o.b.BlockSize() }
+
+func (o opaqueBlock) Encrypt(dst, src []byte) { o.b.Encrypt(dst, src) }
+
+func (o opaqueBlock) Decrypt(dst, src []byte) { o.b.Decrypt(dst, src) }
+
+func TestNewWithCiphers(t *testing.T) {
+	// https://tools.ietf.org/html/rfc5297#appendix-A.1
+	macKey, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0")
+	encKey, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	expected, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	mac, _ := aes.NewCipher(macKey)
+	enc, _ := aes.NewCipher(encKey)
+
+	aead, err := NewWithCiphers(opaqueBlock{mac}, opaqueBlock{enc})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+	if !bytes.Equal(ciphertext, expected) {
+		t.Errorf("Ciphertext was %x, but expected %x", ciphertext, expected)
+	}
+
+	actual, err := aead.Open(nil, nil, ciphertext, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+
+	if err := aead.Reset(append(macKey, encKey...)); err != errNoAlg {
+		t.Errorf("Reset error was %v, but expected %v", err, errNoAlg)
+	}
+}
+
+func TestNewWithCiphersInvalid(t *testing.T) {
+	aesBlock, _ := aes.NewCipher(make([]byte, 16))
+	desBlock, _ := des.NewTripleDESCipher(make([]byte, 24))
+
+	for _, v := range []struct {
+		mac, enc cipher.Block
+		err      error
+	}{
+		{nil, aesBlock, errNilBlock},
+		{aesBlock, nil, errNilBlock},
+		{aesBlock, desBlock, errBlockSize},
+		{stubBlock(32), stubBlock(32), errBlockSize},
+	} {
+		if _, err := NewWithCiphers(v.mac, v.enc); err != v.err {
+			t.Errorf("Error was %v, but expected %v", err, v.err)
+		}
+	}
+}
+
+func TestSubkeys(t *testing.T) {
+	// https://tools.ietf.org/html/rfc4493#section-4
+	macKey, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	encKey, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	expectedK1, _ := hex.DecodeString("fbeed618357133667c85e08f7236a8de")
+	expectedK2, _ := hex.DecodeString("f7ddac306ae266ccf90bc11ee46d513b")
+
+	aead, err := New(append(macKey, encKey...), aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k1, k2 := aead.subkeys()
+
+	if !bytes.Equal(k1, expectedK1) {
+		t.Errorf("K1 was %x, but expected %x", k1, expectedK1)
+	}
+
+	if !bytes.Equal(k2, expectedK2) {
+		t.Errorf("K2 was %x, but expected %x", k2, expectedK2)
+	}
+}
@@ -0,0 +1,68 @@
+package sivtest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestGenerateDeterministic(t *testing.T) {
+	// https://tools.ietf.org/html/rfc5297#appendix-A.1
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	ad, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	s2v, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc93")
+	ciphertext, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	v, err := Generate(key, [][]byte{ad}, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(v.S2V, s2v) {
+		t.Errorf("S2V was %x, but expected %x", v.S2V, s2v)
+	}
+
+	if !bytes.Equal(v.Ciphertext, ciphertext) {
+		t.Errorf("Ciphertext was %x, but expected %x", v.Ciphertext, ciphertext)
+	}
+}
+
+func TestGenerateNonceBased(t *testing.T) {
+	// https://tools.ietf.org/html/rfc5297#appendix-A.2
+	key, _ := hex.DecodeString("7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f")
+	ad1, _ := hex.DecodeString("00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100")
+	ad2, _ := hex.DecodeString("102030405060708090a0")
+	nonce, _ := hex.DecodeString("09f911029d74e35bd84156c5635688c0")
+	plaintext, _ := hex.DecodeString("7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553")
+	s2v, _ := hex.DecodeString("7bdb6e3b432667eb06f4d14bff2fbd0f")
+	ciphertext, _ := hex.DecodeString("7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17dba77ceb094fa663b7a3f748ba8af829ea64ad544a272e9c485b62a3fd5c0d")
+
+	v, err := Generate(key, [][]byte{ad1, ad2, nonce}, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(v.S2V, s2v) {
+		t.Errorf("S2V was %x, but expected %x", v.S2V, s2v)
+	}
+
+	if !bytes.Equal(v.Ciphertext, ciphertext) {
+		t.Errorf("Ciphertext was %x, but expected %x", v.Ciphertext, ciphertext)
+	}
+}
+
+func TestString(t *testing.T) {
+	v := TestVector{
+		Key:        []byte{0x01},
+		AD:         [][]byte{{0x02}, {}},
+		Plaintext:  []byte{0x03},
+		S2V:        []byte{0x04},
+		Ciphertext: []byte{0x04, 0x05},
+	}
+
+	expected := "key: 01\nad: 02\nad: \nplaintext: 03\ns2v: 04\nciphertext: 0405\n"
+	if actual := v.String(); actual != expected {
+		t.Errorf("String was %q, but expected %q", actual, expected)
+	}
+}
@@ -0,0 +1,294 @@
+package siv
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	// streamSegmentSize is the plaintext size of each segment written by
+	// StreamSeal.
+	streamSegmentSize = 64 * 1024
+
+	// maxStreamSegmentSize bounds the segment size StreamOpen will accept,
+	// and with it the memory an untrusted header can make it allocate.
+	maxStreamSegmentSize = 16 * 1024 * 1024
+
+	streamHeaderSize = 4
+	streamNonceSize  = 9
+)
+
+var (
+	errStreamHeader = errors.New("invalid stream header")
+	errStreamClosed = errors.New("stream writer closed")
+)
+
+// StreamSeal encrypts src to dst using the STREAM construction: the plaintext
+// is split into fixed-size segments, each sealed with the given associated
+// data and a nonce made of its position in the stream and a flag marking the
+// final segment. The output starts with a small header recording the segment
+// size, which every segment authenticates as an associated data component
+// preceding the data, so it cannot be altered without the stream failing to
+// open. At most one segment is held in memory at a time.
+func (s *SIV) StreamSeal(dst io.Writer, src io.Reader, data []byte) error {
+	return s.streamSeal(dst, src, data, streamSegmentSize)
+}
+
+// StreamOpen decrypts a stream written by StreamSeal from src to dst. Each
+// segment is authenticated before it is written to dst, but a truncated or
+// reordered stream is only detected when the affected segment is reached, so
+// the output must not be trusted until StreamOpen returns nil.
+func (s *SIV) StreamOpen(dst io.Writer, src io.Reader, data []byte) error {
+	r, err := s.NewStreamReader(src, data)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, r)
+	return err
+}
+
+func (s *SIV) streamSeal(dst io.Writer, src io.Reader, data []byte, size int) error {
+	w, err := s.newStreamWriter(dst, data, size)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, src); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// StreamWriter seals everything written to it as a stream in the format of
+// StreamSeal. Close must be called to write the final segment; a stream
+// which was not closed is rejected as truncated when opened.
+type StreamWriter struct {
+	s      *SIV
+	w      io.Writer
+	header [streamHeaderSize]byte
+	data   []byte
+	size   int
+	buf    []byte
+	out    []byte
+	i      uint64
+	err    error
+}
+
+// NewStreamWriter returns a StreamWriter sealing to w with the given
+// associated data, having written the stream header.
+func (s *SIV) NewStreamWriter(w io.Writer, data []byte) (*StreamWriter, error) {
+	return s.newStreamWriter(w, data, streamSegmentSize)
+}
+
+// NewEncryptingWriter is s.NewStreamWriter(w, data), for composing with other
+// io.Writer wrappers.
+func NewEncryptingWriter(s *SIV, w io.Writer, data []byte) (*StreamWriter, error) {
+	return s.NewStreamWriter(w, data)
+}
+
+func (s *SIV) newStreamWriter(w io.Writer, data []byte, size int) (*StreamWriter, error) {
+	// checked here too, so that no header is written
+	if err := s.checkNonce(nil); err != nil {
+		return nil, err
+	}
+
+	if s.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	sw := &StreamWriter{
+		s:    s,
+		w:    w,
+		data: data,
+		size: size,
+		buf:  make([]byte, 0, size),
+	}
+
+	binary.BigEndian.PutUint32(sw.header[:], uint32(size))
+	if _, err := w.Write(sw.header[:]); err != nil {
+		return nil, err
+	}
+
+	return sw, nil
+}
+
+// Write buffers p, sealing and writing out each segment once it is full
+// and more data follows.
+func (w *StreamWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	n := 0
+	for len(p) > 0 {
+		// A full segment is only written once more input arrives, as the
+		// final segment is sealed differently.
+		if len(w.buf) == w.size {
+			if err := w.flush(false); err != nil {
+				return n, err
+			}
+		}
+
+		c := copy(w.buf[len(w.buf):w.size], p)
+		w.buf = w.buf[:len(w.buf)+c]
+		n += c
+		p = p[c:]
+	}
+
+	return n, nil
+}
+
+// Close seals and writes the final segment. It does not close the
+// underlying writer.
+func (w *StreamWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if err := w.flush(true); err != nil {
+		return err
+	}
+
+	w.err = errStreamClosed
+	return nil
+}
+
+func (w *StreamWriter) flush(last bool) error {
+	if w.s.closed.Load() {
+		w.err = ErrClosed
+		return w.err
+	}
+
+	out, err := w.s.seal(w.out[:0], w.buf, nil, w.header[:], w.data, streamNonce(w.i, last))
+	if err != nil {
+		w.err = err
+		return err
+	}
+
+	w.out = out
+	if _, err := w.w.Write(w.out); err != nil {
+		w.err = err
+		return err
+	}
+
+	w.i++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// StreamReader opens a stream written by StreamSeal or a StreamWriter,
+// returning its plaintext a segment at a time. Each segment is authenticated
+// before any of it is returned, but truncation is only detected at the end, so
+// the output must not be trusted until Read returns io.EOF.
+type StreamReader struct {
+	s      *SIV
+	r      io.Reader
+	header [streamHeaderSize]byte
+	data   []byte
+	buf    []byte
+	carry  int
+	out    []byte
+	pos    int
+	i      uint64
+	err    error
+}
+
+// NewStreamReader returns a StreamReader opening the stream read from r with
+// the given associated data, having read and checked the stream header.
+func (s *SIV) NewStreamReader(r io.Reader, data []byte) (*StreamReader, error) {
+	var header [streamHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errStreamHeader
+		}
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[:])
+	if size == 0 || size > maxStreamSegmentSize {
+		return nil, errStreamHeader
+	}
+
+	// The extra byte in buf is used to look ahead for the end of r, so that
+	// a stream which is an exact multiple of the segment size still ends
+	// with a final segment.
+	return &StreamReader{
+		s:      s,
+		r:      r,
+		header: header,
+		data:   data,
+		buf:    make([]byte, int(size)+s.Overhead()+1),
+	}, nil
+}
+
+// NewDecryptingReader is s.NewStreamReader(r, data), for composing with other
+// io.Reader wrappers.
+func NewDecryptingReader(s *SIV, r io.Reader, data []byte) (*StreamReader, error) {
+	return s.NewStreamReader(r, data)
+}
+
+func (r *StreamReader) Read(p []byte) (int, error) {
+	for r.pos == len(r.out) {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.next()
+	}
+
+	n := copy(p, r.out[r.pos:])
+	r.pos += n
+	return n, nil
+}
+
+// next reads and opens the next segment, returning io.EOF once the final
+// segment has been read.
+func (r *StreamReader) next() error {
+	if r.carry < 0 {
+		return io.EOF
+	}
+
+	size := len(r.buf) - 1
+	n, err := io.ReadFull(r.r, r.buf[r.carry:])
+	n += r.carry
+
+	last := err == io.EOF || err == io.ErrUnexpectedEOF
+	if err != nil && !last {
+		return err
+	}
+
+	segment := r.buf[:size]
+	if last {
+		segment = r.buf[:n]
+	}
+
+	if len(segment) < r.s.Overhead() {
+		return ErrAuthentication
+	}
+
+	out, err := r.s.open(r.out[:0], segment, nil, r.header[:], r.data, streamNonce(r.i, last))
+	if err != nil {
+		return err
+	}
+	r.out, r.pos = out, 0
+	r.i++
+
+	if last {
+		r.carry = -1
+	} else {
+		r.buf[0] = r.buf[size]
+		r.carry = 1
+	}
+	return nil
+}
+
+func streamNonce(i uint64, last bool) []byte {
+	nonce := make([]byte, streamNonceSize)
+	binary.BigEndian.PutUint64(nonce, i)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
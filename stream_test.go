@@ -0,0 +1,247 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+func TestStreamRoundTrip(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 1, streamSegmentSize, 2*streamSegmentSize + streamSegmentSize/2} {
+		plaintext := bytes.Repeat([]byte{0xa5}, n)
+
+		ciphertext := new(bytes.Buffer)
+		if err := aead.StreamSeal(ciphertext, bytes.NewReader(plaintext), data); err != nil {
+			t.Fatal(err)
+		}
+
+		actual := new(bytes.Buffer)
+		if err := aead.StreamOpen(actual, ciphertext, data); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual.Bytes(), plaintext) {
+			t.Errorf("Plaintext of %d bytes did not round trip", n)
+		}
+	}
+}
+
+func TestStreamDroppedSegment(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext := make([]byte, 100)
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := new(bytes.Buffer)
+	if err := aead.streamSeal(ciphertext, bytes.NewReader(plaintext), data, 32); err != nil {
+		t.Fatal(err)
+	}
+
+	// four segments: three full ones of 32 bytes and a final one of 4
+	truncated := ciphertext.Bytes()[:streamHeaderSize+3*(32+aead.Overhead())]
+
+	err = aead.StreamOpen(new(bytes.Buffer), bytes.NewReader(truncated), data)
+	if err == nil {
+		t.Fatal("Truncated stream opened without error")
+	}
+}
+
+func TestStreamTamperedHeader(t *testing.T) {
+	aead, _ := New(make([]byte, 32), aes.NewCipher)
+	plaintext := make([]byte, 10)
+
+	ciphertext := new(bytes.Buffer)
+	if err := aead.streamSeal(ciphertext, bytes.NewReader(plaintext), nil, 16); err != nil {
+		t.Fatal(err)
+	}
+
+	// the only segment is shorter than either size, so the segmentation is
+	// unchanged, and only the authentication of the header can catch this
+	tampered := ciphertext.Bytes()
+	binary.BigEndian.PutUint32(tampered, 32)
+
+	if err := aead.StreamOpen(new(bytes.Buffer), bytes.NewReader(tampered), nil); err != ErrAuthentication {
+		t.Errorf("Error for a tampered header was %v, but expected %v", err, ErrAuthentication)
+	}
+}
+
+func TestStreamBadHeader(t *testing.T) {
+	aead, _ := New(make([]byte, 32), aes.NewCipher)
+
+	for _, header := range [][]byte{nil, {0, 0}, {0, 0, 0, 0}, {0xff, 0xff, 0xff, 0xff}} {
+		err := aead.StreamOpen(new(bytes.Buffer), bytes.NewReader(header), nil)
+		if err != errStreamHeader {
+			t.Errorf("Error for header %x was %v, but expected %v", header, err, errStreamHeader)
+		}
+	}
+}
+
+func TestStreamOpenClosed(t *testing.T) {
+	aead, _ := New(make([]byte, 32), aes.NewCipher)
+
+	ciphertext := new(bytes.Buffer)
+	if err := aead.StreamSeal(ciphertext, bytes.NewReader(make([]byte, 100)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = aead.Close()
+
+	if err := aead.StreamOpen(new(bytes.Buffer), ciphertext, nil); err != ErrClosed {
+		t.Errorf("Error was %v, but expected %v", err, ErrClosed)
+	}
+}
+
+func TestStreamWriterReader(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 1, 31, 32, 33, 100} {
+		plaintext := bytes.Repeat([]byte{0xa5}, n)
+
+		expected := new(bytes.Buffer)
+		if err := aead.streamSeal(expected, bytes.NewReader(plaintext), data, 32); err != nil {
+			t.Fatal(err)
+		}
+
+		// written a byte at a time, to cross segment boundaries
+		ciphertext := new(bytes.Buffer)
+		w, err := aead.newStreamWriter(ciphertext, data, 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range plaintext {
+			if _, err := w.Write(plaintext[i : i+1]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(ciphertext.Bytes(), expected.Bytes()) {
+			t.Errorf("StreamWriter output for %d bytes differed from StreamSeal", n)
+		}
+
+		r, err := aead.NewStreamReader(ciphertext, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual, plaintext) {
+			t.Errorf("Plaintext of %d bytes did not round trip", n)
+		}
+	}
+}
+
+func TestStreamWriterNotClosed(t *testing.T) {
+	aead, _ := New(make([]byte, 32), aes.NewCipher)
+
+	ciphertext := new(bytes.Buffer)
+	w, err := aead.newStreamWriter(ciphertext, nil, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write(make([]byte, 100)); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := aead.NewStreamReader(ciphertext, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := io.ReadAll(r); err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v", err, ErrAuthentication)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write([]byte{1}); err != errStreamClosed {
+		t.Errorf("Write after Close returned %v, but expected %v", err, errStreamClosed)
+	}
+}
+
+func TestStreamReorderedSegments(t *testing.T) {
+	aead, _ := New(make([]byte, 32), aes.NewCipher)
+
+	ciphertext := new(bytes.Buffer)
+	if err := aead.streamSeal(ciphertext, bytes.NewReader(make([]byte, 100)), nil, 32); err != nil {
+		t.Fatal(err)
+	}
+
+	// swap the first two segments
+	b := ciphertext.Bytes()
+	n := 32 + aead.Overhead()
+	first := append([]byte(nil), b[streamHeaderSize:streamHeaderSize+n]...)
+	copy(b[streamHeaderSize:], b[streamHeaderSize+n:streamHeaderSize+2*n])
+	copy(b[streamHeaderSize+n:], first)
+
+	r, err := aead.NewStreamReader(bytes.NewReader(b), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := io.ReadAll(r); err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v", err, ErrAuthentication)
+	}
+}
+
+func TestEncryptingWriterPipe(t *testing.T) {
+	aead, _ := New(make([]byte, 32), aes.NewCipher)
+	data := []byte("file.txt")
+	plaintext := bytes.Repeat([]byte("0123456789"), 20000)
+
+	pr, pw := io.Pipe()
+	go func() {
+		w, err := NewEncryptingWriter(aead, pw, data)
+		if err == nil {
+			_, err = io.Copy(w, bytes.NewReader(plaintext))
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	r, err := NewDecryptingReader(aead, pr, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext of %d bytes did not round trip through a pipe", len(plaintext))
+	}
+}
@@ -0,0 +1,53 @@
+package siv
+
+import (
+	"errors"
+)
+
+var (
+	errStrictConfig = errors.New("invalid configuration for strict mode")
+)
+
+// checkConfig returns an error if, in strict mode, any option was given a value
+// which would otherwise be silently treated as its default. Outside strict
+// mode a negative nonce size is treated as none, so that NonceSize is never
+// negative.
+func (s *SIV) checkConfig() error {
+	if s.strict && (s.nonceSize < 0 || s.chunkSize < 0 || s.maxOpenLen < 0 || s.ctrWorkers < 0 || s.ctrMinSize < 0) {
+		return errStrictConfig
+	}
+
+	if s.nonceSize < 0 {
+		s.nonceSize = 0
+	}
+
+	return nil
+}
+
+// checkNonce returns errNonceSize unless nonce is acceptable. It is the one
+// place the nonce rules are applied, and every method which seals or opens
+// goes through it, those with no nonce of their own passing nil.
+//
+// Outside strict mode, and unless a nonce size was set with WithNonceSize, any
+// nonce is acceptable; otherwise it must be nil if no nonce size is
+// configured, and exactly that size if one is. An empty but non-nil nonce is
+// then always rejected, as it would be authenticated as an empty component
+// where a nil one is not, and two peers could disagree over which was meant.
+func (s *SIV) checkNonce(nonce []byte) error {
+	if !s.strict && !s.exactNonce {
+		return nil
+	}
+
+	if s.nonceSize == 0 {
+		if nonce != nil {
+			return errNonceSize
+		}
+		return nil
+	}
+
+	if len(nonce) != s.nonceSize {
+		return errNonceSize
+	}
+
+	return nil
+}
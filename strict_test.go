@@ -0,0 +1,134 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestStrictNonce(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	lenient, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	strict, err := New(key, aes.NewCipher, WithStrict())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sized, err := New(key, aes.NewCipher, WithStrict(), WithNonceSize(12))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// an empty nonce is accepted, and differs from no nonce, when lenient
+	ciphertext := lenient.Seal(nil, []byte{}, plaintext, data)
+	if _, err := lenient.Open(nil, []byte{}, ciphertext, data); err != nil {
+		t.Errorf("Lenient Open with an empty nonce failed: %v", err)
+	}
+
+	for _, v := range []struct {
+		name  string
+		aead  *SIV
+		nonce []byte
+	}{
+		{"an empty nonce", strict, []byte{}},
+		{"an unexpected nonce", strict, make([]byte, 12)},
+		{"no nonce", sized, nil},
+		{"a short nonce", sized, make([]byte, 11)},
+	} {
+		if actual, err := v.aead.Open(nil, v.nonce, ciphertext, data); err != errNonceSize {
+			t.Errorf("Error opening with %s was %v, but expected %v (plaintext %x)", v.name, err, errNonceSize, actual)
+		}
+
+		if actual, err := v.aead.NewSealer().Open(nil, v.nonce, ciphertext, data); err != errNonceSize {
+			t.Errorf("Sealer error opening with %s was %v, but expected %v (plaintext %x)", v.name, err, errNonceSize, actual)
+		}
+
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Seal with %s did not panic", v.name)
+				}
+			}()
+
+			v.aead.Seal(nil, v.nonce, plaintext, data)
+		}()
+	}
+
+	// well-formed nonces work as before
+	if actual := strict.Seal(nil, nil, plaintext, data); !bytes.Equal(actual, lenient.Seal(nil, nil, plaintext, data)) {
+		t.Errorf("Strict ciphertext was %x, but expected the lenient one", actual)
+	}
+
+	nonce := make([]byte, 12)
+	if _, err := sized.Open(nil, nonce, sized.Seal(nil, nonce, plaintext, data), data); err != nil {
+		t.Errorf("Strict Open with a correctly sized nonce failed: %v", err)
+	}
+
+	// internal framing with its own nonces is unaffected
+	buf := new(bytes.Buffer)
+	if err := strict.StreamSeal(buf, bytes.NewReader(plaintext), data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := strict.StreamOpen(new(bytes.Buffer), buf, data); err != nil {
+		t.Errorf("Strict StreamOpen failed: %v", err)
+	}
+}
+
+func TestStrictConfig(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	for _, opt := range []Option{WithNonceSize(-1), WithChunkSize(-1), WithMaxOpenLen(-1)} {
+		if _, err := New(key, aes.NewCipher, opt); err != nil {
+			t.Errorf("Lenient configuration failed: %v", err)
+		}
+
+		if _, err := New(key, aes.NewCipher, opt, WithStrict()); err != errStrictConfig {
+			t.Errorf("Error for a strict configuration was %v, but expected %v", err, errStrictConfig)
+		}
+	}
+}
+
+func TestStrictCookie(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	lenient, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	strict, err := New(key, aes.NewCipher, WithStrict())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a 17-byte ciphertext leaves two unused bits in its last character
+	encoded, err := lenient.NewCookieSealer().Seal("session", []byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	last := bytes.IndexByte([]byte(alphabet), encoded[len(encoded)-1])
+	noncanonical := encoded[:len(encoded)-1] + string(alphabet[last|1])
+
+	if _, err := lenient.NewCookieSealer().Open("session", noncanonical); err != nil {
+		t.Errorf("Lenient Open of a non-canonical encoding failed: %v", err)
+	}
+
+	if _, err := strict.NewCookieSealer().Open("session", noncanonical); err != ErrAuthentication {
+		t.Errorf("Error for a non-canonical encoding was %v, but expected %v", err, ErrAuthentication)
+	}
+
+	if _, err := strict.NewCookieSealer().Open("session", encoded); err != nil {
+		t.Errorf("Strict Open of a canonical encoding failed: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package siv
+
+import (
+	"errors"
+)
+
+var (
+	errTinkKeySize = errors.New("invalid Tink AES-SIV key size: must be 64 bytes")
+)
+
+// A TinkAEAD adapts an SIV AEAD to the Encrypt and Decrypt methods of Tink's
+// AEAD interface, for code being migrated from Tink. The output is that of
+// Seal with no nonce: the synthetic IV followed by the ciphertext.
+type TinkAEAD struct {
+	s *SIV
+}
+
+// NewTinkAEAD returns a TinkAEAD using the AEAD s.
+func (s *SIV) NewTinkAEAD() *TinkAEAD {
+	return &TinkAEAD{s: s}
+}
+
+// Encrypt seals plaintext with associatedData as Seal does with no nonce.
+func (a *TinkAEAD) Encrypt(plaintext, associatedData []byte) ([]byte, error) {
+	if a.s.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	return a.s.seal(nil, plaintext, nil, associatedData)
+}
+
+// Decrypt opens a ciphertext produced by Encrypt or by Seal with no nonce.
+func (a *TinkAEAD) Decrypt(ciphertext, associatedData []byte) ([]byte, error) {
+	return a.s.open(nil, ciphertext, nil, associatedData)
+}
+
+// A TinkDeterministicAEAD implements Tink's DeterministicAEAD interface with
+// AES-SIV, producing the same ciphertexts as Tink's own AES-SIV primitive, so
+// that it can stand in for it inside Tink keysets. Unlike TinkAEAD, the
+// associated data is always authenticated, as an empty component if nil, as
+// Tink does.
+type TinkDeterministicAEAD struct {
+	s *SIV
+}
+
+// NewTinkDeterministicAEAD returns a TinkDeterministicAEAD with the given key,
+// which must be 64 bytes, the only size Tink supports for AES-SIV.
+func NewTinkDeterministicAEAD(key []byte) (*TinkDeterministicAEAD, error) {
+	if len(key) != 64 {
+		return nil, errTinkKeySize
+	}
+
+	s, err := NewAES256(key)
+	if err != nil {
+		return nil, err
+	}
+	return &TinkDeterministicAEAD{s: s}, nil
+}
+
+// EncryptDeterministically seals plaintext with associatedData, returning the
+// synthetic IV followed by the ciphertext.
+func (a *TinkDeterministicAEAD) EncryptDeterministically(plaintext, associatedData []byte) ([]byte, error) {
+	if a.s.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	return a.s.seal(nil, plaintext, nil, nonNil(associatedData))
+}
+
+// DecryptDeterministically opens a ciphertext produced by
+// EncryptDeterministically or by Tink's AES-SIV.
+func (a *TinkDeterministicAEAD) DecryptDeterministically(ciphertext, associatedData []byte) ([]byte, error) {
+	return a.s.open(nil, ciphertext, nil, nonNil(associatedData))
+}
@@ -0,0 +1,108 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestTinkAEAD(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	expected, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tink interface {
+		Encrypt(plaintext, associatedData []byte) ([]byte, error)
+		Decrypt(ciphertext, associatedData []byte) ([]byte, error)
+	} = aead.NewTinkAEAD()
+
+	ciphertext, err := tink.Encrypt(plaintext, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(ciphertext, expected) {
+		t.Errorf("Ciphertext was %x, but expected %x", ciphertext, expected)
+	}
+
+	if sealed := aead.Seal(nil, nil, plaintext, data); !bytes.Equal(ciphertext, sealed) {
+		t.Errorf("Ciphertext was %x, but Seal gave %x", ciphertext, sealed)
+	}
+
+	actual, err := tink.Decrypt(ciphertext, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Plaintext was %x, but expected %x", actual, plaintext)
+	}
+
+	if actual, err := tink.Decrypt(ciphertext, data[1:]); err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v (plaintext %x)", err, ErrAuthentication, actual)
+	}
+}
+
+func TestTinkDeterministicAEAD(t *testing.T) {
+	key := make([]byte, 64)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	plaintext := []byte("hello, world")
+
+	var tink interface {
+		EncryptDeterministically(plaintext, associatedData []byte) ([]byte, error)
+		DecryptDeterministically(ciphertext, associatedData []byte) ([]byte, error)
+	}
+
+	tink, err := NewTinkDeterministicAEAD(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// computed with OpenSSL's AES-256-SIV, with the associated data as the
+	// only component, as Tink does
+	for _, v := range []struct {
+		data       []byte
+		ciphertext string
+	}{
+		{nil, "75ff5221ce96c443a110edaaeab709e07bedee7294a93f2def8a9c4a"},
+		{[]byte{}, "75ff5221ce96c443a110edaaeab709e07bedee7294a93f2def8a9c4a"},
+		{[]byte("associated data"), "4f59d1ede559ea5b806891c14435796a493e8609e2ebb236031b083f"},
+	} {
+		expected, _ := hex.DecodeString(v.ciphertext)
+
+		ciphertext, err := tink.EncryptDeterministically(plaintext, v.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(ciphertext, expected) {
+			t.Errorf("Ciphertext was %x, but expected %x", ciphertext, expected)
+		}
+
+		actual, err := tink.DecryptDeterministically(ciphertext, v.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual, plaintext) {
+			t.Errorf("Plaintext was %q, but expected %q", actual, plaintext)
+		}
+	}
+
+	if _, err := tink.DecryptDeterministically(make([]byte, 28), nil); err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v", err, ErrAuthentication)
+	}
+
+	if _, err := NewTinkDeterministicAEAD(key[:32]); err != errTinkKeySize {
+		t.Errorf("Error for a 32-byte key was %v, but expected %v", err, errTinkKeySize)
+	}
+}
@@ -0,0 +1,80 @@
+package siv
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+)
+
+const (
+	tokenVersion    = 1
+	tokenPrefixSize = 6
+
+	// algorithm IDs recorded in tokens
+	tokenSIV     = 1
+	tokenPMACSIV = 2
+)
+
+// A Tokenizer deterministically encrypts values into tokens for storage, for
+// instance in database columns which are looked up by equality: the same
+// value and context always give the same token while the primary key of its
+// keyset is unchanged.
+//
+// Each token is unpadded URL-safe base64 of a version byte of 1, an algorithm
+// ID byte (1 for SIV, 2 for PMAC-SIV), and the key ID as a 4-byte big-endian
+// integer, followed by a ciphertext sealed with that 6-byte prefix and the
+// context as separate components.
+type Tokenizer struct {
+	k *Keyset
+}
+
+// NewTokenizer returns a Tokenizer using the keys of k.
+func NewTokenizer(k *Keyset) *Tokenizer {
+	return &Tokenizer{k: k}
+}
+
+// Tokenize returns the token for value in the given context, which is
+// authenticated but not encrypted; a token only detokenizes in the context it
+// was made for. Tokens for equal values differ once the primary key changes,
+// so lookups of values tokenized before a rotation must try the old keys.
+func (t *Tokenizer) Tokenize(value, context []byte) (string, error) {
+	id, s, err := t.k.primaryKey()
+	if err != nil {
+		return "", err
+	}
+
+	out := make([]byte, tokenPrefixSize, tokenPrefixSize+s.Overhead()+len(value))
+	out[0] = tokenVersion
+	out[1] = tokenAlgorithm(s)
+	binary.BigEndian.PutUint32(out[2:], id)
+
+	out, err = s.seal(out, value, nil, out[:tokenPrefixSize], nonNil(context))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+// Detokenize returns the value of a token made by Tokenize with any key still
+// in the keyset. A token which is malformed, names an unknown key, version or
+// algorithm, or was made for another context returns ErrAuthentication.
+func (t *Tokenizer) Detokenize(token string, context []byte) ([]byte, error) {
+	b, err := base64.RawURLEncoding.Strict().DecodeString(token)
+	if err != nil || len(b) < tokenPrefixSize || b[0] != tokenVersion {
+		return nil, ErrAuthentication
+	}
+
+	s, ok := t.k.key(binary.BigEndian.Uint32(b[2:]))
+	if !ok || b[1] != tokenAlgorithm(s) {
+		return nil, ErrAuthentication
+	}
+
+	return s.open(b[tokenPrefixSize:tokenPrefixSize], b[tokenPrefixSize:], nil, b[:tokenPrefixSize], nonNil(context))
+}
+
+func tokenAlgorithm(s *SIV) byte {
+	if s.pmac {
+		return tokenPMACSIV
+	}
+	return tokenSIV
+}
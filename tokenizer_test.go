@@ -0,0 +1,93 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestTokenizer(t *testing.T) {
+	k := NewKeyset()
+	if err := k.Add(7, newKeysetKey(t, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.SetPrimary(7); err != nil {
+		t.Fatal(err)
+	}
+
+	tok := NewTokenizer(k)
+	value := []byte("4242424242424242")
+	context := []byte("users.card_number")
+
+	token, err := tok.Tokenize(value, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, _ := tok.Tokenize(value, context)
+	if again != token {
+		t.Errorf("Token was %s, but expected %s", again, token)
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []byte{1, 1, 0, 0, 0, 7}; !bytes.Equal(b[:6], expected) {
+		t.Errorf("Prefix was %x, but expected %x", b[:6], expected)
+	}
+
+	actual, err := tok.Detokenize(token, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, value) {
+		t.Errorf("Value was %q, but expected %q", actual, value)
+	}
+
+	for _, bad := range []string{"", "AQ", token[:len(token)-1], token + "A", "*" + token[1:]} {
+		if _, err := tok.Detokenize(bad, context); err != ErrAuthentication {
+			t.Errorf("Error for %q was %v, but expected %v", bad, err, ErrAuthentication)
+		}
+	}
+
+	if _, err := tok.Detokenize(token, []byte("users.email")); err != ErrAuthentication {
+		t.Errorf("Error in another context was %v, but expected %v", err, ErrAuthentication)
+	}
+
+	// a token may not claim another algorithm, even for the same key
+	b[1] = 2
+	if _, err := tok.Detokenize(base64.RawURLEncoding.EncodeToString(b), context); err != ErrAuthentication {
+		t.Errorf("Error for another algorithm was %v, but expected %v", err, ErrAuthentication)
+	}
+}
+
+func TestTokenizerPMAC(t *testing.T) {
+	aead, err := NewPMACSIV(bytes.Repeat([]byte{3}, 32), aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k := NewKeyset()
+	_ = k.Add(1, aead)
+	_ = k.SetPrimary(1)
+
+	tok := NewTokenizer(k)
+	token, err := tok.Tokenize([]byte("value"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := base64.RawURLEncoding.DecodeString(token)
+	if b[1] != 2 {
+		t.Errorf("Algorithm ID was %d, but expected 2", b[1])
+	}
+
+	// nil and empty contexts are the same
+	if _, err := tok.Detokenize(token, []byte{}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,73 @@
+package siv
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// variants lists the SIV constructions benchmarked against one another, with
+// the files in testdata/vectors holding the published vectors for each.
+var variants = []struct {
+	name    string
+	new     func(key []byte) (*SIV, error)
+	vectors []string
+}{
+	{"CMAC", func(key []byte) (*SIV, error) { return New(key, aes.NewCipher) }, []string{"rfc5297.json"}},
+	{"PMAC", func(key []byte) (*SIV, error) { return NewPMACSIV(key, aes.NewCipher) }, []string{"miscreant-pmac.json"}},
+}
+
+func TestVariantVectors(t *testing.T) {
+	for _, v := range variants {
+		newAEAD := func(key []byte, _ func([]byte) (cipher.Block, error), _ ...Option) (*SIV, error) {
+			return v.new(key)
+		}
+
+		for _, file := range v.vectors {
+			t.Run(v.name+"/"+file, func(t *testing.T) {
+				vf := loadVectors(t, filepath.Join("testdata", "vectors", file))
+
+				mode := vf.Mode
+				if mode == "" {
+					mode = "CMAC"
+				}
+				if mode != v.name {
+					t.Fatalf("Vector file %s is for %s, not %s", file, mode, v.name)
+				}
+
+				for i, e := range vf.Vectors {
+					e.check(t, i, newAEAD)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkCMACvsPMAC(b *testing.B) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	for _, size := range []int{16, 1024, 64 * 1024, 1024 * 1024} {
+		plaintext := make([]byte, size)
+		dst := make([]byte, 0, size+aes.BlockSize)
+
+		for _, v := range variants {
+			aead, err := v.new(key)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.Run(fmt.Sprintf("%s/%d", v.name, size), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(size))
+
+				for i := 0; i < b.N; i++ {
+					aead.Seal(dst, nil, plaintext, data)
+				}
+			})
+		}
+	}
+}
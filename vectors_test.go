@@ -0,0 +1,147 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// vectorFile is the schema of the known-answer test files in testdata/vectors.
+// Each file is a JSON object with a description and a list of vectors, whose
+// fields are hex strings:
+//
+//	{
+//	  "description": "where the vectors came from",
+//	  "mode": "optional; CMAC (the default) or PMAC",
+//	  "vectors": [
+//	    {
+//	      "comment": "optional",
+//	      "key": "...",
+//	      "ad": ["...", "..."],
+//	      "plaintext": "...",
+//	      "s2v": "optional; the synthetic IV",
+//	      "ciphertext": "the synthetic IV followed by the ciphertext"
+//	    }
+//	  ]
+//	}
+//
+// The associated data components are passed to SealMulti in order, on an AEAD
+// from New or, for PMAC files, NewPMACSIV. Unknown
+// fields are an error, so that a misspelt field cannot silently go unchecked.
+type vectorFile struct {
+	Description string        `json:"description"`
+	Mode        string        `json:"mode"`
+	Vectors     []vectorEntry `json:"vectors"`
+}
+
+type vectorEntry struct {
+	Comment    string   `json:"comment"`
+	Key        string   `json:"key"`
+	AD         []string `json:"ad"`
+	Plaintext  string   `json:"plaintext"`
+	S2V        string   `json:"s2v"`
+	Ciphertext string   `json:"ciphertext"`
+}
+
+func TestVectorFiles(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "vectors", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) == 0 {
+		t.Fatal("No test vectors found in testdata/vectors")
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			vf := loadVectors(t, file)
+
+			newAEAD := New
+			switch vf.Mode {
+			case "", "CMAC":
+			case "PMAC":
+				newAEAD = NewPMACSIV
+			default:
+				t.Fatalf("Vector file %s has unknown mode %q", file, vf.Mode)
+			}
+
+			for i, v := range vf.Vectors {
+				v.check(t, i, newAEAD)
+			}
+		})
+	}
+}
+
+func loadVectors(t *testing.T, file string) vectorFile {
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var vf vectorFile
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&vf); err != nil {
+		t.Fatalf("Malformed vector file %s: %v", file, err)
+	}
+
+	if len(vf.Vectors) == 0 {
+		t.Fatalf("Vector file %s has no vectors", file)
+	}
+
+	return vf
+}
+
+// decode decodes a hex field of vector i, failing the test if it is malformed.
+func decode(t *testing.T, i int, field, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("Vector %d has a malformed %s: %v", i, field, err)
+	}
+	return b
+}
+
+func (v vectorEntry) check(t *testing.T, i int, newAEAD func([]byte, func([]byte) (cipher.Block, error), ...Option) (*SIV, error)) {
+	if v.Key == "" || v.Ciphertext == "" {
+		t.Fatalf("Vector %d is missing its key or ciphertext", i)
+	}
+
+	key := decode(t, i, "key", v.Key)
+	plaintext := decode(t, i, "plaintext", v.Plaintext)
+	expected := decode(t, i, "ciphertext", v.Ciphertext)
+
+	ad := make([][]byte, len(v.AD))
+	for j, s := range v.AD {
+		ad[j] = decode(t, i, "ad", s)
+	}
+
+	aead, err := newAEAD(key, aes.NewCipher)
+	if err != nil {
+		t.Fatalf("Vector %d (%s): %v", i, v.Comment, err)
+	}
+
+	ciphertext := aead.SealMulti(nil, plaintext, ad...)
+	if !bytes.Equal(ciphertext, expected) {
+		t.Errorf("Vector %d (%s): ciphertext was %x, but expected %x", i, v.Comment, ciphertext, expected)
+	}
+
+	if v.S2V != "" {
+		if s2v := decode(t, i, "s2v", v.S2V); !bytes.Equal(expected[:len(s2v)], s2v) {
+			t.Errorf("Vector %d (%s): S2V was %x, but the ciphertext begins %x", i, v.Comment, s2v, expected[:len(s2v)])
+		}
+	}
+
+	actual, err := aead.OpenMulti(nil, expected, ad...)
+	if err != nil {
+		t.Errorf("Vector %d (%s): %v", i, v.Comment, err)
+	} else if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Vector %d (%s): plaintext was %x, but expected %x", i, v.Comment, actual, plaintext)
+	}
+}
@@ -0,0 +1,89 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"io"
+)
+
+// VerifyStream reads a ciphertext from src and reports whether it is
+// authentic for the given associated data, returning nil if so and
+// ErrAuthentication if not. The plaintext is recomputed to check the synthetic
+// IV but is not retained, so only a constant amount of memory is used however
+// long the ciphertext is.
+func (s *SIV) VerifyStream(src io.Reader, data []byte) error {
+	if err := s.checkNonce(nil); err != nil {
+		return err
+	}
+
+	if s.closed.Load() {
+		return ErrClosed
+	}
+
+	prefix := make([]byte, s.Overhead())
+	if _, err := io.ReadFull(src, prefix); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ErrAuthentication
+		}
+		return err
+	}
+
+	if !bytes.HasPrefix(prefix, s.magic) {
+		return ErrAuthentication
+	}
+	v := prefix[len(s.magic):]
+
+	st := newS2V(s.newMAC())
+	if s.magic != nil {
+		st.add(s.magic)
+	}
+	if data != nil {
+		st.add(data)
+	}
+	w := newS2VWriter(st)
+
+	ctr := s.stream(v)
+	buf := make([]byte, 32*1024)
+
+	for {
+		n, err := src.Read(buf)
+		ctr.XORKeyStream(buf[:n], buf[:n])
+		_, _ = w.Write(buf[:n])
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if subtle.ConstantTimeCompare(v, w.Sum()) != 1 {
+		return ErrAuthentication
+	}
+
+	return nil
+}
+
+// ExpectTag recomputes the synthetic IV of plaintext and data, as sealed by
+// Seal with no nonce, and compares it in constant time with tag, returning
+// ErrAuthentication if they differ. Unlike Open and VerifyStream it takes the
+// plaintext rather than the ciphertext, and so does no encryption.
+func (s *SIV) ExpectTag(plaintext, data, tag []byte) error {
+	if err := s.checkNonce(nil); err != nil {
+		return err
+	}
+
+	if s.closed.Load() {
+		return ErrClosed
+	}
+
+	sc := s.getScratch()
+	defer s.putScratch(sc)
+	s.bind(sc)
+
+	if subtle.ConstantTimeCompare(tag, sc.s2v(s.magic, [][]byte{data}, nil, plaintext)) != 1 {
+		return ErrAuthentication
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestVerifyStream(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+
+	plaintext := make([]byte, 4*1024*1024+7)
+	for i := range plaintext {
+		plaintext[i] = byte(i % 251)
+	}
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+
+	if err := aead.VerifyStream(bytes.NewReader(ciphertext), data); err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext[len(ciphertext)/2] ^= 1
+
+	if err := aead.VerifyStream(bytes.NewReader(ciphertext), data); err != ErrAuthentication {
+		t.Errorf("Error was %v, but expected %v", err, ErrAuthentication)
+	}
+}
+
+func TestVerifyStreamShort(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := aead.Seal(nil, nil, plaintext, data)
+
+	if err := aead.VerifyStream(bytes.NewReader(ciphertext), data); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, aead.Overhead() - 1, aead.Overhead()} {
+		err := aead.VerifyStream(bytes.NewReader(ciphertext[:n]), data)
+		if err != ErrAuthentication {
+			t.Errorf("Error for %d bytes was %v, but expected %v", n, err, ErrAuthentication)
+		}
+	}
+}
+
+func TestExpectTag(t *testing.T) {
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext, _ := hex.DecodeString("112233445566778899aabbccddee")
+	tag, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc93")
+
+	aead, err := New(key, aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := aead.ExpectTag(plaintext, data, tag); err != nil {
+		t.Fatal(err)
+	}
+
+	long := bytes.Repeat(plaintext, 10)
+	if err := aead.ExpectTag(long, nil, aead.Seal(nil, nil, long, nil)[:16]); err != nil {
+		t.Errorf("Tag of a sealed %d-byte plaintext did not match: %v", len(long), err)
+	}
+
+	if err := aead.ExpectTag(plaintext, data[1:], tag); err != ErrAuthentication {
+		t.Errorf("Error with the wrong data was %v, but expected %v", err, ErrAuthentication)
+	}
+
+	if err := aead.ExpectTag(plaintext[1:], data, tag); err != ErrAuthentication {
+		t.Errorf("Error with the wrong plaintext was %v, but expected %v", err, ErrAuthentication)
+	}
+
+	if err := aead.ExpectTag(plaintext, data, tag[:15]); err != ErrAuthentication {
+		t.Errorf("Error with a short tag was %v, but expected %v", err, ErrAuthentication)
+	}
+}
@@ -0,0 +1,47 @@
+package siv
+
+import (
+	"errors"
+)
+
+// minWrappedKeySize is the smallest key WrapKey will wrap, that of AES-128.
+const minWrappedKeySize = 16
+
+var (
+	errWrappedKeySize = errors.New("invalid key size: keys to wrap must be at least 16 bytes")
+)
+
+// WrapKey wraps key with the key-encryption key kek, which must be 32, 48 or
+// 64 bytes, using AES-SIV as a deterministic key wrap as described in section
+// 1.3.1 of RFC 5297. The context, which may be nil, is authenticated with the
+// key, and the result is 16 bytes longer than key.
+func WrapKey(kek, key, context []byte) ([]byte, error) {
+	if len(key) < minWrappedKeySize {
+		return nil, errWrappedKeySize
+	}
+
+	s, err := NewAES(kek)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	return s.seal(nil, key, nil, nonNil(context))
+}
+
+// UnwrapKey unwraps a key wrapped by WrapKey with the same key-encryption key
+// and context. A wrapped key which has been modified or was wrapped with a
+// different context returns ErrAuthentication.
+func UnwrapKey(kek, wrapped, context []byte) ([]byte, error) {
+	s, err := NewAES(kek)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	if len(wrapped) < s.Overhead()+minWrappedKeySize {
+		return nil, ErrAuthentication
+	}
+
+	return s.open(nil, wrapped, nil, nonNil(context))
+}
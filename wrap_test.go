@@ -0,0 +1,56 @@
+package siv
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestWrapKey(t *testing.T) {
+	kek, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	context, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	key, _ := hex.DecodeString("00112233445566778899aabbccddeeff")
+	expected, _ := hex.DecodeString("b8f0a4e3f399b23d5faee045d9307ccdb34b97f1da01419c4232a3f116503282")
+
+	wrapped, err := WrapKey(kek, key, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(wrapped, expected) {
+		t.Errorf("Wrapped key was %x, but expected %x", wrapped, expected)
+	}
+
+	actual, err := UnwrapKey(kek, wrapped, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, key) {
+		t.Errorf("Key was %x, but expected %x", actual, key)
+	}
+
+	if _, err := UnwrapKey(kek, wrapped, nil); err != ErrAuthentication {
+		t.Errorf("Error with another context was %v, but expected %v", err, ErrAuthentication)
+	}
+
+	if _, err := UnwrapKey(kek, wrapped[:31], context); err != ErrAuthentication {
+		t.Errorf("Error for a short wrapped key was %v, but expected %v", err, ErrAuthentication)
+	}
+}
+
+func TestWrapKeyInvalid(t *testing.T) {
+	kek := make([]byte, 32)
+
+	if _, err := WrapKey(kek, make([]byte, 15), nil); err != errWrappedKeySize {
+		t.Errorf("Error for a 15-byte key was %v, but expected %v", err, errWrappedKeySize)
+	}
+
+	if _, err := WrapKey(kek[:16], make([]byte, 16), nil); err != errAESKeySize {
+		t.Errorf("Error for a 16-byte key-encryption key was %v, but expected %v", err, errAESKeySize)
+	}
+
+	if _, err := UnwrapKey(kek[:16], make([]byte, 32), nil); err != errAESKeySize {
+		t.Errorf("Error for a 16-byte key-encryption key was %v, but expected %v", err, errAESKeySize)
+	}
+}